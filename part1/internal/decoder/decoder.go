@@ -6,6 +6,20 @@ import (
 	"github.com/8086-simulator/part1/internal/instruction"
 )
 
+const lockPrefix = 0b11110000
+
+var segmentPrefixEnc = map[byte]string{
+	0b00100110: "es",
+	0b00101110: "cs",
+	0b00110110: "ss",
+	0b00111110: "ds",
+}
+
+var repPrefixEnc = map[byte]string{
+	0b11110010: "repne",
+	0b11110011: "rep",
+}
+
 type Decoder struct{}
 
 func NewDecoder() *Decoder {
@@ -16,39 +30,91 @@ func (d *Decoder) Decode(data []byte) ([]*instruction.Instruction, error) {
 	i := 0
 	var instructions []*instruction.Instruction
 	for i < len(data) {
-		instrFound := false
-		for _, p := range instruction.Table {
-			parsedOpCode := p.GetOpCode(data, i)
-			if parsedOpCode != p.OpCode {
-				continue
-			}
-
-			instrFound = true
-			ins := instruction.NewInstruction(data, i, p)
-			ins.DBit = p.GetDBit(data, i)
-			ins.WBit = p.GetWBit(data, i)
-			ins.Reg = p.GetReg(data, i)
-			ins.RM = p.GetRM(data, i)
-			ins.Mod = p.GetMod(data, i)
-			ins.SBit = p.GetSBit(data, i)
-			ins.DestRegister = p.GetDestRegister(ins)
-			ins.SourceRegister = p.GetSourceRegister(ins)
-			ins.SourceDisplacement = p.GetSourceDisplacement(data, i, ins)
-			ins.DestDisplacement = p.GetDestDisplacement(data, i, ins)
-			ins.Immediate = p.GetImmediate(data, i, ins)
-			ins.SourceAddr = p.GetSorceAddr(data, i, ins)
-			ins.DestAddr = p.GetDestAddr(data, i, ins)
-			ins.Text = p.GetText(p, ins)
-			instructions = append(instructions, ins)
-			i += p.GetBytesCount(p, ins)
-			ins.IPRegister = i
+		ins, err := d.DecodeAt(data, i)
+		if err != nil {
+			return nil, err
+		}
+		instructions = append(instructions, ins)
+		i = ins.IPRegister
+	}
+
+	return instructions, nil
+}
+
+// DecodeAt decodes the single instruction starting at index i of data, including any prefixes.
+// The returned instruction's IPRegister points at the byte following it.
+func (d *Decoder) DecodeAt(data []byte, i int) (*instruction.Instruction, error) {
+	start := i
+	var segmentOverride, rep string
+	var lock bool
+	for i < len(data) {
+		if seg, ok := segmentPrefixEnc[data[i]]; ok {
+			segmentOverride = seg
+		} else if r, ok := repPrefixEnc[data[i]]; ok {
+			rep = r
+		} else if data[i] == lockPrefix {
+			lock = true
+		} else {
 			break
 		}
+		i++
+	}
+	if i >= len(data) {
+		return nil, fmt.Errorf("prefix without instruction at index %d", start)
+	}
+
+	for _, p := range instruction.Table {
+		parsedOpCode := p.GetOpCode(data, i)
+		if parsedOpCode != p.OpCode {
+			continue
+		}
 
-		if !instrFound {
-			return nil, fmt.Errorf("instruction not found at index %d with opcode %d", i, data[i])
+		ins := instruction.NewInstruction(data, i, p)
+		ins.SegmentOverride = segmentOverride
+		ins.Rep = rep
+		ins.Lock = lock
+		ins.DBit = p.GetDBit(data, i)
+		ins.WBit = p.GetWBit(data, i)
+		ins.Reg = p.GetReg(data, i)
+		ins.RM = p.GetRM(data, i)
+		ins.Mod = p.GetMod(data, i)
+		ins.SBit = p.GetSBit(data, i)
+		ins.Op = p.GetOp(p, ins)
+		if ins.Op == "" {
+			// group opcodes take their operation from the reg field, and not every value is defined
+			return nil, fmt.Errorf("undefined reg field %03b for opcode 0x%02x at index %d", ins.Reg, data[i], i)
 		}
+		ins.DestRegister = p.GetDestRegister(ins)
+		ins.SourceRegister = p.GetSourceRegister(ins)
+		ins.SourceDisplacement = p.GetSourceDisplacement(data, i, ins)
+		ins.DestDisplacement = p.GetDestDisplacement(data, i, ins)
+		ins.Immediate = p.GetImmediate(data, i, ins)
+		ins.SourceAddr = p.GetSorceAddr(data, i, ins)
+		ins.DestAddr = p.GetDestAddr(data, i, ins)
+		ins.Text = prefixText(ins) + p.GetText(p, ins)
+		ins.Size = i - start + p.GetBytesCount(p, ins)
+		ins.IPRegister = start + ins.Size
+		return ins, nil
 	}
 
-	return instructions, nil
+	return nil, fmt.Errorf("instruction not found at index %d with opcode %d", i, data[i])
+}
+
+// prefixText returns the prefixes written before the mnemonic. Segment overrides are shown inside
+// memory operands, except for string instructions which have no explicit operands.
+func prefixText(ins *instruction.Instruction) string {
+	text := ""
+	if ins.Lock {
+		text += "lock "
+	}
+	if ins.SegmentOverride != "" && ins.OperandType == instruction.OpTypeString {
+		text += ins.SegmentOverride + " "
+	}
+	switch {
+	case ins.Rep == "rep" && (ins.Op == instruction.CMPS || ins.Op == instruction.SCAS):
+		text += "repe "
+	case ins.Rep != "":
+		text += ins.Rep + " "
+	}
+	return text
 }
@@ -2,7 +2,10 @@ package decoder
 
 import (
 	"os"
+	"strings"
 	"testing"
+
+	"github.com/8086-simulator/part1/internal/instruction"
 )
 
 func TestDecoderListing37(t *testing.T) {
@@ -212,3 +215,315 @@ func TestDecoderListing41(t *testing.T) {
 		}
 	}
 }
+
+func TestDecoderPrefixes(t *testing.T) {
+	content := []byte{
+		0xf3, 0xa4, // rep movsb
+		0xf3, 0xa6, // repe cmpsb
+		0xf2, 0xae, // repne scasb
+		0xad,       // lodsw
+		0xf3, 0xab, // rep stosw
+		0x26, 0x8b, 0x07, // mov ax, es:[bx]
+		0x2e, 0xa4, // cs movsb
+		0xf0, 0x53, // lock push bx
+		0xfc, // cld
+		0xfd, // std
+	}
+	expectedInstructions := []string{
+		"rep movsb",
+		"repe cmpsb",
+		"repne scasb",
+		"lodsw",
+		"rep stosw",
+		"mov ax, es:[bx]",
+		"cs movsb",
+		"lock push bx",
+		"cld",
+		"std",
+	}
+
+	decoder := NewDecoder()
+	instructions, err := decoder.Decode(content)
+	if err != nil {
+		t.Fatalf("Error decoding data: %v", err)
+	}
+
+	if len(instructions) != len(expectedInstructions) {
+		t.Fatalf("Expected %d instructions but got %d", len(expectedInstructions), len(instructions))
+	}
+	for i, instruction := range instructions {
+		if instruction.Text != expectedInstructions[i] {
+			t.Fatalf("Expected instruction %s but got %s", expectedInstructions[i], instruction.Text)
+		}
+	}
+}
+
+func TestDecoderLogical(t *testing.T) {
+	content := []byte{
+		0x21, 0xd8, // and ax, bx
+		0x0b, 0x4f, 0x02, // or cx, [bx + 2]
+		0x34, 0x0f, // xor al, 15
+		0x80, 0x27, 0x0f, // and byte [bx], 15
+		0x83, 0xc9, 0xff, // or cx, -1
+		0x84, 0xc3, // test bl, al
+		0xa8, 0x01, // test al, 1
+		0xf7, 0xc1, 0x34, 0x12, // test cx, 4660
+		0xf6, 0xd0, // not al
+		0xf7, 0x5e, 0x02, // neg word [bp + 2]
+		0xd1, 0xe0, // shl ax, 1
+		0xd2, 0x2f, // shr byte [bx], cl
+		0xd3, 0xdb, // rcr bx, cl
+		0xd1, 0xf9, // sar cx, 1
+	}
+	expectedInstructions := []string{
+		"and ax, bx",
+		"or cx, [bx + 2]",
+		"xor al, 15",
+		"and byte [bx], 15",
+		"or cx, -1",
+		"test bl, al",
+		"test al, 1",
+		"test cx, 4660",
+		"not al",
+		"neg word [bp + 2]",
+		"shl ax, 1",
+		"shr byte [bx], cl",
+		"rcr bx, cl",
+		"sar cx, 1",
+	}
+
+	decoder := NewDecoder()
+	instructions, err := decoder.Decode(content)
+	if err != nil {
+		t.Fatalf("Error decoding data: %v", err)
+	}
+
+	if len(instructions) != len(expectedInstructions) {
+		t.Fatalf("Expected %d instructions but got %d", len(expectedInstructions), len(instructions))
+	}
+	for i, instruction := range instructions {
+		if instruction.Text != expectedInstructions[i] {
+			t.Fatalf("Expected instruction %s but got %s", expectedInstructions[i], instruction.Text)
+		}
+	}
+}
+
+func TestDecoderUndefinedGroupEncodings(t *testing.T) {
+	tests := []struct {
+		content  []byte
+		expected string
+	}{
+		{content: []byte{0xf6, 0xc8, 0x01}, expected: "undefined reg field 001 for opcode 0xf6"},
+		{content: []byte{0xf7, 0x08, 0x34, 0x12}, expected: "undefined reg field 001 for opcode 0xf7"},
+		{content: []byte{0xd0, 0xf0}, expected: "undefined reg field 110 for opcode 0xd0"},
+		{content: []byte{0xd3, 0x37}, expected: "undefined reg field 110 for opcode 0xd3"},
+	}
+
+	decoder := NewDecoder()
+	for _, tt := range tests {
+		if _, err := decoder.Decode(tt.content); err == nil || !strings.Contains(err.Error(), tt.expected) {
+			t.Fatalf("Expected error %q for % x but got %v", tt.expected, tt.content, err)
+		}
+	}
+}
+
+func TestDecoderDecimalAdjust(t *testing.T) {
+	content := []byte{
+		0x27,       // daa
+		0x2f,       // das
+		0x37,       // aaa
+		0x3f,       // aas
+		0xd4, 0x0a, // aam
+		0xd4, 0x10, // aam 16
+		0xd5, 0x0a, // aad
+		0xd5, 0x07, // aad 7
+	}
+	expectedInstructions := []string{
+		"daa",
+		"das",
+		"aaa",
+		"aas",
+		"aam",
+		"aam 16",
+		"aad",
+		"aad 7",
+	}
+
+	decoder := NewDecoder()
+	instructions, err := decoder.Decode(content)
+	if err != nil {
+		t.Fatalf("Error decoding data: %v", err)
+	}
+
+	if len(instructions) != len(expectedInstructions) {
+		t.Fatalf("Expected %d instructions but got %d", len(expectedInstructions), len(instructions))
+	}
+	for i, instruction := range instructions {
+		if instruction.Text != expectedInstructions[i] {
+			t.Fatalf("Expected instruction %s but got %s", expectedInstructions[i], instruction.Text)
+		}
+	}
+}
+
+func TestDecoderInterrupts(t *testing.T) {
+	content := []byte{
+		0xcd, 0x21, // int 33
+		0xcc, // int3
+		0xce, // into
+		0xcf, // iret
+		0xfa, // cli
+		0xfb, // sti
+	}
+	expectedInstructions := []string{
+		"int 33",
+		"int3",
+		"into",
+		"iret",
+		"cli",
+		"sti",
+	}
+
+	decoder := NewDecoder()
+	instructions, err := decoder.Decode(content)
+	if err != nil {
+		t.Fatalf("Error decoding data: %v", err)
+	}
+
+	if len(instructions) != len(expectedInstructions) {
+		t.Fatalf("Expected %d instructions but got %d", len(expectedInstructions), len(instructions))
+	}
+	for i, instruction := range instructions {
+		if instruction.Text != expectedInstructions[i] {
+			t.Fatalf("Expected instruction %s but got %s", expectedInstructions[i], instruction.Text)
+		}
+	}
+}
+
+func TestDecoderPorts(t *testing.T) {
+	content := []byte{
+		0xe4, 0x40, // in al, 64
+		0xe5, 0x60, // in ax, 96
+		0xec,       // in al, dx
+		0xed,       // in ax, dx
+		0xe6, 0x43, // out 67, al
+		0xe7, 0x20, // out 32, ax
+		0xee, // out dx, al
+		0xef, // out dx, ax
+	}
+	expectedInstructions := []string{
+		"in al, 64",
+		"in ax, 96",
+		"in al, dx",
+		"in ax, dx",
+		"out 67, al",
+		"out 32, ax",
+		"out dx, al",
+		"out dx, ax",
+	}
+
+	decoder := NewDecoder()
+	instructions, err := decoder.Decode(content)
+	if err != nil {
+		t.Fatalf("Error decoding data: %v", err)
+	}
+
+	if len(instructions) != len(expectedInstructions) {
+		t.Fatalf("Expected %d instructions but got %d", len(expectedInstructions), len(instructions))
+	}
+	for i, instruction := range instructions {
+		if instruction.Text != expectedInstructions[i] {
+			t.Fatalf("Expected instruction %s but got %s", expectedInstructions[i], instruction.Text)
+		}
+	}
+}
+
+func TestDecoderGroupFF(t *testing.T) {
+	content := []byte{
+		0xff, 0xc0, // inc ax
+		0xff, 0x0f, // dec word [bx]
+		0xff, 0xe3, // jmp bx
+		0xff, 0x76, 0x02, // push word [bp + 2]
+	}
+	expectedInstructions := []string{
+		"inc ax",
+		"dec word [bx]",
+		"jmp bx",
+		"push word [bp + 2]",
+	}
+
+	decoder := NewDecoder()
+	instructions, err := decoder.Decode(content)
+	if err != nil {
+		t.Fatalf("Error decoding data: %v", err)
+	}
+
+	if len(instructions) != len(expectedInstructions) {
+		t.Fatalf("Expected %d instructions but got %d", len(expectedInstructions), len(instructions))
+	}
+	for i, instruction := range instructions {
+		if instruction.Text != expectedInstructions[i] {
+			t.Fatalf("Expected instruction %s but got %s", expectedInstructions[i], instruction.Text)
+		}
+	}
+
+	// reg 111 is not an instruction
+	if _, err := decoder.Decode([]byte{0xff, 0xf8}); err == nil || !strings.Contains(err.Error(), "0xff") {
+		t.Fatalf("Expected an error naming opcode 0xff but got %v", err)
+	}
+}
+
+func TestDecoderShortForms(t *testing.T) {
+	content := []byte{
+		0xa1, 0x34, 0x12, // mov ax, [4660]
+		0xa2, 0x00, 0x01, // mov [256], al
+		0x26, 0xa3, 0x02, 0x00, // mov es:[2], ax
+		0x40, // inc ax
+		0x4f, // dec di
+	}
+	expectedInstructions := []string{
+		"mov ax, [bp + 4660]",
+		"mov [bp + 256], al",
+		"mov es:[bp + 2], ax",
+		"inc ax",
+		"dec di",
+	}
+
+	decoder := NewDecoder()
+	instructions, err := decoder.Decode(content)
+	if err != nil {
+		t.Fatalf("Error decoding data: %v", err)
+	}
+
+	if len(instructions) != len(expectedInstructions) {
+		t.Fatalf("Expected %d instructions but got %d", len(expectedInstructions), len(instructions))
+	}
+	for i, instruction := range instructions {
+		if instruction.Text != expectedInstructions[i] {
+			t.Fatalf("Expected instruction %s but got %s", expectedInstructions[i], instruction.Text)
+		}
+	}
+}
+
+func TestDecoderGroupOps(t *testing.T) {
+	tests := []struct {
+		content  []byte
+		expected instruction.Op
+	}{
+		{content: []byte{0x83, 0xe8, 0x01}, expected: instruction.SUB}, // sub ax, 1
+		{content: []byte{0xff, 0xf3}, expected: instruction.PUSH},      // push bx
+		{content: []byte{0xf7, 0xd9}, expected: instruction.NEG},       // neg cx
+		{content: []byte{0xd1, 0xfa}, expected: instruction.SAR},       // sar dx, 1
+	}
+
+	decoder := NewDecoder()
+	for _, tt := range tests {
+		// the operation is set while decoding, before and apart from the text
+		ins, err := decoder.DecodeAt(tt.content, 0)
+		if err != nil {
+			t.Fatalf("Error decoding data: %v", err)
+		}
+		if ins.Op != tt.expected {
+			t.Fatalf("Expected op %s for % x but got %q", tt.expected, tt.content, ins.Op)
+		}
+	}
+}
@@ -34,13 +34,67 @@ const (
 	LOOPZ  Op = "loopz"
 	LOOPNZ Op = "loopnz"
 	JCXZ   Op = "jcxz"
+	HLT    Op = "hlt"
+	PUSH   Op = "push"
+	POP    Op = "pop"
+	PUSHF  Op = "pushf"
+	POPF   Op = "popf"
+	CALL   Op = "call"
+	CALLF  Op = "call far"
+	JMP    Op = "jmp"
+	JMPF   Op = "jmp far"
+	RET    Op = "ret"
+	RETF   Op = "retf"
+	MUL    Op = "mul"
+	IMUL   Op = "imul"
+	DIV    Op = "div"
+	IDIV   Op = "idiv"
+	MOVS   Op = "movs"
+	CMPS   Op = "cmps"
+	SCAS   Op = "scas"
+	LODS   Op = "lods"
+	STOS   Op = "stos"
+	CLD    Op = "cld"
+	STD    Op = "std"
+	AND    Op = "and"
+	OR     Op = "or"
+	XOR    Op = "xor"
+	TEST   Op = "test"
+	NOT    Op = "not"
+	NEG    Op = "neg"
+	SHL    Op = "shl"
+	SHR    Op = "shr"
+	SAR    Op = "sar"
+	ROL    Op = "rol"
+	ROR    Op = "ror"
+	RCL    Op = "rcl"
+	RCR    Op = "rcr"
+	DAA    Op = "daa"
+	DAS    Op = "das"
+	AAA    Op = "aaa"
+	AAS    Op = "aas"
+	AAM    Op = "aam"
+	AAD    Op = "aad"
+	INT    Op = "int"
+	INT3   Op = "int3"
+	INTO   Op = "into"
+	IRET   Op = "iret"
+	CLI    Op = "cli"
+	STI    Op = "sti"
+	IN     Op = "in"
+	OUT    Op = "out"
+	INC    Op = "inc"
+	DEC    Op = "dec"
 )
 
 type OperandType int
 
 var regOpCodeEnc = map[byte]Op{
 	0b000: ADD,
+	0b001: OR,
+	0b100: AND,
 	0b101: SUB,
+	0b110: XOR,
 	0b111: CMP,
 }
 
@@ -49,8 +103,48 @@ const (
 	OpTypeImmToReg
 	OpTypeImmToAcc
 	OpTypeJump
+	OpTypeNone
+	OpTypeRegister
+	OpTypeRegMem
+	OpTypeFarJump
+	OpTypeString
+	OpTypeMemToFromAcc
 )
 
+// groupFFOpEnc maps the reg field of the 0xFF opcode group to its operation. 111 is undefined.
+var groupFFOpEnc = map[byte]Op{
+	0b000: INC,
+	0b001: DEC,
+	0b010: CALL,
+	0b011: CALLF,
+	0b100: JMP,
+	0b101: JMPF,
+	0b110: PUSH,
+}
+
+// shiftOpEnc maps the reg field of the 0xD0-0xD3 shift and rotate group to its operation.
+// SAL shares the SHL encoding, and 110 is undefined.
+var shiftOpEnc = map[byte]Op{
+	0b000: ROL,
+	0b001: ROR,
+	0b010: RCL,
+	0b011: RCR,
+	0b100: SHL,
+	0b101: SHR,
+	0b111: SAR,
+}
+
+// groupF6OpEnc maps the reg field of the 0xF6/0xF7 opcode group to its operation. 001 is undefined.
+var groupF6OpEnc = map[byte]Op{
+	0b000: TEST,
+	0b010: NOT,
+	0b011: NEG,
+	0b100: MUL,
+	0b101: IMUL,
+	0b110: DIV,
+	0b111: IDIV,
+}
+
 var regFieldEnc = map[byte]map[bool]string{
 	0b000: {false: "al", true: "ax"},
 	0b001: {false: "cl", true: "cx"},
@@ -62,6 +156,13 @@ var regFieldEnc = map[byte]map[bool]string{
 	0b111: {false: "bh", true: "di"},
 }
 
+var segRegFieldEnc = map[byte]string{
+	0b00: "es",
+	0b01: "cs",
+	0b10: "ss",
+	0b11: "ds",
+}
+
 var effectiveAddrEnc = map[byte]map[byte]string{
 	0b00: {
 		0b000: "bx + si",
@@ -119,6 +220,10 @@ type Instruction struct {
 	SourceDisplacement []byte
 	DestDisplacement   []byte
 	IPRegister         int
+	Size               int
+	SegmentOverride    string
+	Rep                string
+	Lock               bool
 }
 
 // NewInstruction creates a new Instruction with all default values
@@ -133,6 +238,9 @@ func NewInstruction(instructions []byte, i int, p *Pattern) *Instruction {
 func (ins *Instruction) formatOperand(addr string, displacement []byte, register string) string {
 	if addr != "" {
 		result := fmt.Sprintf("[%s", addr)
+		if ins.SegmentOverride != "" {
+			result = fmt.Sprintf("%s:[%s", ins.SegmentOverride, addr)
+		}
 		if len(displacement) > 0 {
 			if len(displacement) == 1 {
 				result += fmt.Sprintf(" + %d", bits.ToSigned8(displacement[0]))
@@ -207,18 +315,50 @@ func (ins *Instruction) GetImmToRegInstrByteCount() int {
 	return defaultInc
 }
 
+// GetRegMemInstrByteCount returns the size of an opcode, mod r/m byte and displacement.
+func (ins *Instruction) GetRegMemInstrByteCount() int {
+	if ins.Mod == 0b00 && ins.RM == 0b110 {
+		return 4
+	} else if ins.Mod == 0b01 {
+		return 3
+	} else if ins.Mod == 0b10 {
+		return 4
+	}
+	return 2
+}
+
+// GetRegMemText formats an instruction whose only operand is encoded in the mod r/m byte.
+func (ins *Instruction) GetRegMemText() string {
+	dest := ins.formatOperand(ins.DestAddr, ins.DestDisplacement, ins.DestRegister)
+	if ins.DestAddr == "" {
+		return fmt.Sprintf("%s %s", ins.Op, dest)
+	}
+	switch ins.Op {
+	case CALL, CALLF, JMP, JMPF:
+		return fmt.Sprintf("%s %s", ins.Op, dest)
+	}
+	insType := "byte"
+	if ins.WBit {
+		insType = "word"
+	}
+	return fmt.Sprintf("%s %s %s", ins.Op, insType, dest)
+}
+
 type Pattern struct {
-	OpCode                byte
-	Op                    Op
-	GetOpCode             func(instructions []byte, i int) byte
-	OperandType           OperandType
-	GetBytesCount         func(p *Pattern, ins *Instruction) int
-	GetDBit               func(instructions []byte, i int) bool
-	GetWBit               func(instructions []byte, i int) bool
-	GetSBit               func(instructions []byte, i int) bool
-	GetMod                func(instructions []byte, i int) byte
-	GetReg                func(instructions []byte, i int) byte
-	GetRM                 func(instructions []byte, i int) byte
+	OpCode        byte
+	Op            Op
+	GetOpCode     func(instructions []byte, i int) byte
+	OperandType   OperandType
+	GetBytesCount func(p *Pattern, ins *Instruction) int
+	GetDBit       func(instructions []byte, i int) bool
+	GetWBit       func(instructions []byte, i int) bool
+	GetSBit       func(instructions []byte, i int) bool
+	GetMod        func(instructions []byte, i int) byte
+	GetReg        func(instructions []byte, i int) byte
+	GetRM         func(instructions []byte, i int) byte
+	// GetOp returns the operation once the fields above are decoded; group opcodes take it from the reg
+	// field, and an empty Op means the encoding is undefined.
+	GetOp                 func(p *Pattern, ins *Instruction) Op
 	GetDestRegister       func(ins *Instruction) string
 	GetSourceRegister     func(ins *Instruction) string
 	GetText               func(p *Pattern, ins *Instruction) string
@@ -240,6 +380,7 @@ func NewPattern() *Pattern {
 		GetMod:            func(instructions []byte, i int) byte { return 0 },
 		GetReg:            func(instructions []byte, i int) byte { return 0 },
 		GetRM:             func(instructions []byte, i int) byte { return 0 },
+		GetOp:             func(p *Pattern, _ *Instruction) Op { return p.Op },
 		GetDestRegister:   func(ins *Instruction) string { return ins.GetDestReg() },
 		GetSourceRegister: func(ins *Instruction) string { return ins.GetSourceReg() },
 		GetText:           func(p *Pattern, ins *Instruction) string { return ins.GetText(p) },
@@ -303,6 +444,24 @@ func NewPattern() *Pattern {
 	}
 }
 
+// NewSingleBytePattern creates a Pattern for an instruction made of a single opcode byte and no operands.
+func NewSingleBytePattern(opCode byte, op Op) *Pattern {
+	p := NewPattern()
+	p.OpCode = opCode
+	p.Op = op
+	p.OperandType = OpTypeNone
+	p.GetOpCode = func(instructions []byte, i int) byte { return bits.GetBits(instructions[i], 0, 8) }
+	p.GetBytesCount = func(_ *Pattern, _ *Instruction) int {
+		return 1
+	}
+	p.GetImmediate = func(_ []byte, _ int, _ *Instruction) *ImmediateData { return nil }
+	p.GetSorceAddr = func(_ []byte, _ int, _ *Instruction) string { return "" }
+	p.GetText = func(p *Pattern, _ *Instruction) string {
+		return string(p.Op)
+	}
+	return p
+}
+
 var Table = []*Pattern{
 	// MOV
 	// MOV - Register/memory to/from register
@@ -447,6 +606,31 @@ var Table = []*Pattern{
 
 		return p
 	}(),
+	// MOV - Memory to/from accumulator. The address is always direct, so this decodes like mod 00 r/m 110
+	// with the accumulator as the register and the direction bit inverted.
+	func() *Pattern {
+		p := NewPattern()
+		p.OpCode = 0b101000
+		p.Op = MOV
+		p.OperandType = OpTypeMemToFromAcc
+		p.GetBytesCount = func(_ *Pattern, _ *Instruction) int {
+			return 3
+		}
+		p.GetImmediate = func(_ []byte, _ int, _ *Instruction) *ImmediateData { return nil }
+		p.GetOpCode = func(instructions []byte, i int) byte { return bits.GetBits(instructions[i], 2, 6) }
+		p.GetDBit = func(instructions []byte, i int) bool { return !bits.GetBit(instructions[i], 1) }
+		p.GetWBit = func(instructions []byte, i int) bool { return bits.GetBit(instructions[i], 0) }
+		p.GetMod = func(_ []byte, _ int) byte { return 0b00 }
+		p.GetReg = func(_ []byte, _ int) byte { return 0b000 }
+		p.GetRM = func(_ []byte, _ int) byte { return 0b110 }
+		p.GetSourceDisplacement = func(instructions []byte, i int, _ *Instruction) []byte {
+			return instructions[i+1 : i+3]
+		}
+		p.GetDestDisplacement = func(instructions []byte, i int, _ *Instruction) []byte {
+			return instructions[i+1 : i+3]
+		}
+		return p
+	}(),
 
 	// ADD
 	// ADD - Register/memory to/from register
@@ -569,7 +753,7 @@ var Table = []*Pattern{
 			case 0b11:
 				inc += 0
 			case 0b01:
-				inc += 3
+				inc += 1
 			case 0b10:
 				inc += 2
 			default:
@@ -583,6 +767,7 @@ var Table = []*Pattern{
 		p.GetReg = func(instructions []byte, i int) byte { return bits.GetBits(instructions[i+1], 3, 3) }
 		p.GetRM = func(instructions []byte, i int) byte { return bits.GetBits(instructions[i+1], 0, 3) }
 		p.GetMod = func(instructions []byte, i int) byte { return bits.GetBits(instructions[i+1], 6, 2) }
+		p.GetOp = func(_ *Pattern, ins *Instruction) Op { return regOpCodeEnc[ins.Reg] }
 		p.GetText = func(p *Pattern, ins *Instruction) string {
 			if !ins.DBit {
 				tmpAddr := ins.SourceAddr
@@ -600,13 +785,11 @@ var Table = []*Pattern{
 				insType = "word"
 			}
 
-			op := regOpCodeEnc[ins.Reg]
-			ins.Op = regOpCodeEnc[ins.Reg]
 			if ins.Mod == 0b11 {
-				return fmt.Sprintf("%s %s, %s", op, dest, source)
+				return fmt.Sprintf("%s %s, %s", ins.Op, dest, source)
 			}
 
-			return fmt.Sprintf("%s %s %s, %s", op, insType, dest, source)
+			return fmt.Sprintf("%s %s %s, %s", ins.Op, insType, dest, source)
 		}
 		p.GetImmediate = func(instructions []byte, i int, ins *Instruction) *ImmediateData {
 			idx := 0
@@ -1162,4 +1345,609 @@ var Table = []*Pattern{
 		}
 		return p
 	}(),
+	// HLT
+	NewSingleBytePattern(0b11110100, HLT),
+	// MOV - Register/memory to/from segment register
+	func() *Pattern {
+		p := NewPattern()
+		p.OpCode = 0b100011
+		p.Op = MOV
+		p.OperandType = OpTypeRegMemToFromReg
+		p.GetBytesCount = func(_ *Pattern, ins *Instruction) int {
+			return ins.GetRegMemInstrByteCount()
+		}
+		p.GetImmediate = func(_ []byte, _ int, _ *Instruction) *ImmediateData { return nil }
+		p.GetOpCode = func(instructions []byte, i int) byte {
+			if bits.GetBit(instructions[i], 0) {
+				return 0
+			}
+			return bits.GetBits(instructions[i], 2, 6)
+		}
+		p.GetDBit = func(instructions []byte, i int) bool { return bits.GetBit(instructions[i], 1) }
+		p.GetWBit = func(_ []byte, _ int) bool { return true }
+		p.GetMod = func(instructions []byte, i int) byte { return bits.GetBits(instructions[i+1], 6, 2) }
+		p.GetReg = func(instructions []byte, i int) byte { return bits.GetBits(instructions[i+1], 3, 2) }
+		p.GetRM = func(instructions []byte, i int) byte { return bits.GetBits(instructions[i+1], 0, 3) }
+		p.GetDestRegister = func(ins *Instruction) string {
+			if ins.DBit {
+				return segRegFieldEnc[ins.Reg]
+			}
+			return regFieldEnc[ins.RM][true]
+		}
+		p.GetSourceRegister = func(ins *Instruction) string {
+			if ins.DBit {
+				return regFieldEnc[ins.RM][true]
+			}
+			return segRegFieldEnc[ins.Reg]
+		}
+		return p
+	}(),
+	// PUSH - Register
+	func() *Pattern {
+		p := NewSingleBytePattern(0b01010, PUSH)
+		p.OperandType = OpTypeRegister
+		p.GetOpCode = func(instructions []byte, i int) byte { return bits.GetBits(instructions[i], 3, 5) }
+		p.GetReg = func(instructions []byte, i int) byte { return bits.GetBits(instructions[i], 0, 3) }
+		p.GetDestRegister = func(ins *Instruction) string { return regFieldEnc[ins.Reg][true] }
+		p.GetSourceRegister = func(_ *Instruction) string { return "" }
+		p.GetText = func(p *Pattern, ins *Instruction) string {
+			return fmt.Sprintf("%s %s", p.Op, ins.DestRegister)
+		}
+		return p
+	}(),
+	// POP - Register
+	func() *Pattern {
+		p := NewSingleBytePattern(0b01011, POP)
+		p.OperandType = OpTypeRegister
+		p.GetOpCode = func(instructions []byte, i int) byte { return bits.GetBits(instructions[i], 3, 5) }
+		p.GetReg = func(instructions []byte, i int) byte { return bits.GetBits(instructions[i], 0, 3) }
+		p.GetDestRegister = func(ins *Instruction) string { return regFieldEnc[ins.Reg][true] }
+		p.GetSourceRegister = func(_ *Instruction) string { return "" }
+		p.GetText = func(p *Pattern, ins *Instruction) string {
+			return fmt.Sprintf("%s %s", p.Op, ins.DestRegister)
+		}
+		return p
+	}(),
+	// PUSH - Segment register
+	func() *Pattern {
+		p := NewSingleBytePattern(0b00000110, PUSH)
+		p.OperandType = OpTypeRegister
+		p.GetOpCode = func(instructions []byte, i int) byte { return instructions[i] & 0b11100111 }
+		p.GetReg = func(instructions []byte, i int) byte { return bits.GetBits(instructions[i], 3, 2) }
+		p.GetDestRegister = func(ins *Instruction) string { return segRegFieldEnc[ins.Reg] }
+		p.GetSourceRegister = func(_ *Instruction) string { return "" }
+		p.GetText = func(p *Pattern, ins *Instruction) string {
+			return fmt.Sprintf("%s %s", p.Op, ins.DestRegister)
+		}
+		return p
+	}(),
+	// INC - Register (word)
+	func() *Pattern {
+		p := NewSingleBytePattern(0b01000, INC)
+		p.OperandType = OpTypeRegister
+		p.GetOpCode = func(instructions []byte, i int) byte { return bits.GetBits(instructions[i], 3, 5) }
+		p.GetWBit = func(_ []byte, _ int) bool { return true }
+		p.GetReg = func(instructions []byte, i int) byte { return bits.GetBits(instructions[i], 0, 3) }
+		p.GetDestRegister = func(ins *Instruction) string { return regFieldEnc[ins.Reg][true] }
+		p.GetSourceRegister = func(_ *Instruction) string { return "" }
+		p.GetText = func(p *Pattern, ins *Instruction) string {
+			return fmt.Sprintf("%s %s", p.Op, ins.DestRegister)
+		}
+		return p
+	}(),
+	// DEC - Register (word)
+	func() *Pattern {
+		p := NewSingleBytePattern(0b01001, DEC)
+		p.OperandType = OpTypeRegister
+		p.GetOpCode = func(instructions []byte, i int) byte { return bits.GetBits(instructions[i], 3, 5) }
+		p.GetWBit = func(_ []byte, _ int) bool { return true }
+		p.GetReg = func(instructions []byte, i int) byte { return bits.GetBits(instructions[i], 0, 3) }
+		p.GetDestRegister = func(ins *Instruction) string { return regFieldEnc[ins.Reg][true] }
+		p.GetSourceRegister = func(_ *Instruction) string { return "" }
+		p.GetText = func(p *Pattern, ins *Instruction) string {
+			return fmt.Sprintf("%s %s", p.Op, ins.DestRegister)
+		}
+		return p
+	}(),
+	// POP - Segment register
+	func() *Pattern {
+		p := NewSingleBytePattern(0b00000111, POP)
+		p.OperandType = OpTypeRegister
+		p.GetOpCode = func(instructions []byte, i int) byte { return instructions[i] & 0b11100111 }
+		p.GetReg = func(instructions []byte, i int) byte { return bits.GetBits(instructions[i], 3, 2) }
+		p.GetDestRegister = func(ins *Instruction) string { return segRegFieldEnc[ins.Reg] }
+		p.GetSourceRegister = func(_ *Instruction) string { return "" }
+		p.GetText = func(p *Pattern, ins *Instruction) string {
+			return fmt.Sprintf("%s %s", p.Op, ins.DestRegister)
+		}
+		return p
+	}(),
+	// POP - Register/memory
+	func() *Pattern {
+		p := NewPattern()
+		p.OpCode = 0b10001111
+		p.Op = POP
+		p.OperandType = OpTypeRegMem
+		p.GetOpCode = func(instructions []byte, i int) byte { return bits.GetBits(instructions[i], 0, 8) }
+		p.GetBytesCount = func(_ *Pattern, ins *Instruction) int {
+			return ins.GetRegMemInstrByteCount()
+		}
+		p.GetWBit = func(_ []byte, _ int) bool { return true }
+		p.GetMod = func(instructions []byte, i int) byte { return bits.GetBits(instructions[i+1], 6, 2) }
+		p.GetReg = func(instructions []byte, i int) byte { return bits.GetBits(instructions[i+1], 3, 3) }
+		p.GetRM = func(instructions []byte, i int) byte { return bits.GetBits(instructions[i+1], 0, 3) }
+		p.GetDestRegister = func(ins *Instruction) string { return regFieldEnc[ins.RM][true] }
+		p.GetSourceRegister = func(_ *Instruction) string { return "" }
+		p.GetImmediate = func(_ []byte, _ int, _ *Instruction) *ImmediateData { return nil }
+		p.GetSorceAddr = func(_ []byte, _ int, _ *Instruction) string { return "" }
+		p.GetDestAddr = func(_ []byte, _ int, ins *Instruction) string {
+			if ins.Mod == 0b11 {
+				return ""
+			}
+			return effectiveAddrEnc[ins.Mod][ins.RM]
+		}
+		p.GetText = func(_ *Pattern, ins *Instruction) string {
+			return ins.GetRegMemText()
+		}
+		return p
+	}(),
+	// INC, DEC, CALL, CALL FAR, JMP, JMP FAR, PUSH - Register/memory (word)
+	func() *Pattern {
+		p := NewPattern()
+		p.OpCode = 0b11111111
+		p.OperandType = OpTypeRegMem
+		p.GetOpCode = func(instructions []byte, i int) byte { return bits.GetBits(instructions[i], 0, 8) }
+		p.GetBytesCount = func(_ *Pattern, ins *Instruction) int {
+			return ins.GetRegMemInstrByteCount()
+		}
+		p.GetWBit = func(_ []byte, _ int) bool { return true }
+		p.GetMod = func(instructions []byte, i int) byte { return bits.GetBits(instructions[i+1], 6, 2) }
+		p.GetReg = func(instructions []byte, i int) byte { return bits.GetBits(instructions[i+1], 3, 3) }
+		p.GetRM = func(instructions []byte, i int) byte { return bits.GetBits(instructions[i+1], 0, 3) }
+		p.GetDestRegister = func(ins *Instruction) string { return regFieldEnc[ins.RM][true] }
+		p.GetSourceRegister = func(_ *Instruction) string { return "" }
+		p.GetImmediate = func(_ []byte, _ int, _ *Instruction) *ImmediateData { return nil }
+		p.GetSorceAddr = func(_ []byte, _ int, _ *Instruction) string { return "" }
+		p.GetDestAddr = func(_ []byte, _ int, ins *Instruction) string {
+			if ins.Mod == 0b11 {
+				return ""
+			}
+			return effectiveAddrEnc[ins.Mod][ins.RM]
+		}
+		p.GetOp = func(_ *Pattern, ins *Instruction) Op { return groupFFOpEnc[ins.Reg] }
+		p.GetText = func(_ *Pattern, ins *Instruction) string {
+			return ins.GetRegMemText()
+		}
+		return p
+	}(),
+	// PUSHF
+	NewSingleBytePattern(0b10011100, PUSHF),
+	// POPF
+	NewSingleBytePattern(0b10011101, POPF),
+	// CALL - Direct within segment
+	func() *Pattern {
+		p := NewSingleBytePattern(0b11101000, CALL)
+		p.OperandType = OpTypeJump
+		p.GetBytesCount = func(_ *Pattern, _ *Instruction) int {
+			return 3
+		}
+		p.GetImmediate = func(instructions []byte, i int, _ *Instruction) *ImmediateData {
+			return &ImmediateData{
+				Raw:      []byte{instructions[i+1], instructions[i+2]},
+				Value:    int(bits.ToSigned16(instructions[i+1], instructions[i+2])),
+				IsSigned: true,
+			}
+		}
+		p.GetText = func(p *Pattern, ins *Instruction) string {
+			return fmt.Sprintf("%s %d", p.Op, ins.Immediate.Value)
+		}
+		return p
+	}(),
+	// CALL - Direct intersegment
+	func() *Pattern {
+		p := NewSingleBytePattern(0b10011010, CALLF)
+		p.OperandType = OpTypeFarJump
+		p.GetBytesCount = func(_ *Pattern, _ *Instruction) int {
+			return 5
+		}
+		p.GetImmediate = func(instructions []byte, i int, _ *Instruction) *ImmediateData {
+			return &ImmediateData{
+				Raw:   []byte{instructions[i+1], instructions[i+2], instructions[i+3], instructions[i+4]},
+				Value: int(bits.ToUnsigned16(instructions[i+1], instructions[i+2])),
+			}
+		}
+		p.GetText = func(p *Pattern, ins *Instruction) string {
+			return fmt.Sprintf("%s %d:%d", p.Op, bits.ToUnsigned16(ins.Immediate.Raw[2], ins.Immediate.Raw[3]), ins.Immediate.Value)
+		}
+		return p
+	}(),
+	// JMP - Direct within segment
+	func() *Pattern {
+		p := NewSingleBytePattern(0b11101001, JMP)
+		p.OperandType = OpTypeJump
+		p.GetBytesCount = func(_ *Pattern, _ *Instruction) int {
+			return 3
+		}
+		p.GetImmediate = func(instructions []byte, i int, _ *Instruction) *ImmediateData {
+			return &ImmediateData{
+				Raw:      []byte{instructions[i+1], instructions[i+2]},
+				Value:    int(bits.ToSigned16(instructions[i+1], instructions[i+2])),
+				IsSigned: true,
+			}
+		}
+		p.GetText = func(p *Pattern, ins *Instruction) string {
+			return fmt.Sprintf("%s %d", p.Op, ins.Immediate.Value)
+		}
+		return p
+	}(),
+	// JMP - Direct within segment-short
+	func() *Pattern {
+		p := NewSingleBytePattern(0b11101011, JMP)
+		p.OperandType = OpTypeJump
+		p.GetBytesCount = func(_ *Pattern, _ *Instruction) int {
+			return 2
+		}
+		p.GetImmediate = func(instructions []byte, i int, _ *Instruction) *ImmediateData {
+			return &ImmediateData{
+				Raw:      []byte{instructions[i+1]},
+				Value:    int(bits.ToSigned8(instructions[i+1])),
+				IsSigned: true,
+			}
+		}
+		p.GetText = func(p *Pattern, ins *Instruction) string {
+			return fmt.Sprintf("%s %d", p.Op, ins.Immediate.Value)
+		}
+		return p
+	}(),
+	// JMP - Direct intersegment
+	func() *Pattern {
+		p := NewSingleBytePattern(0b11101010, JMPF)
+		p.OperandType = OpTypeFarJump
+		p.GetBytesCount = func(_ *Pattern, _ *Instruction) int {
+			return 5
+		}
+		p.GetImmediate = func(instructions []byte, i int, _ *Instruction) *ImmediateData {
+			return &ImmediateData{
+				Raw:   []byte{instructions[i+1], instructions[i+2], instructions[i+3], instructions[i+4]},
+				Value: int(bits.ToUnsigned16(instructions[i+1], instructions[i+2])),
+			}
+		}
+		p.GetText = func(p *Pattern, ins *Instruction) string {
+			return fmt.Sprintf("%s %d:%d", p.Op, bits.ToUnsigned16(ins.Immediate.Raw[2], ins.Immediate.Raw[3]), ins.Immediate.Value)
+		}
+		return p
+	}(),
+	// RET - Within segment
+	NewSingleBytePattern(0b11000011, RET),
+	// RET - Within segment adding immediate to SP
+	func() *Pattern {
+		p := NewSingleBytePattern(0b11000010, RET)
+		p.GetBytesCount = func(_ *Pattern, _ *Instruction) int {
+			return 3
+		}
+		p.GetImmediate = func(instructions []byte, i int, _ *Instruction) *ImmediateData {
+			return &ImmediateData{
+				Raw:   []byte{instructions[i+1], instructions[i+2]},
+				Value: int(bits.ToUnsigned16(instructions[i+1], instructions[i+2])),
+			}
+		}
+		p.GetText = func(p *Pattern, ins *Instruction) string {
+			return fmt.Sprintf("%s %d", p.Op, ins.Immediate.Value)
+		}
+		return p
+	}(),
+	// RET - Intersegment
+	NewSingleBytePattern(0b11001011, RETF),
+	// RET - Intersegment adding immediate to SP
+	func() *Pattern {
+		p := NewSingleBytePattern(0b11001010, RETF)
+		p.GetBytesCount = func(_ *Pattern, _ *Instruction) int {
+			return 3
+		}
+		p.GetImmediate = func(instructions []byte, i int, _ *Instruction) *ImmediateData {
+			return &ImmediateData{
+				Raw:   []byte{instructions[i+1], instructions[i+2]},
+				Value: int(bits.ToUnsigned16(instructions[i+1], instructions[i+2])),
+			}
+		}
+		p.GetText = func(p *Pattern, ins *Instruction) string {
+			return fmt.Sprintf("%s %d", p.Op, ins.Immediate.Value)
+		}
+		return p
+	}(),
+	// TEST (immediate), NOT, NEG, MUL, IMUL, DIV, IDIV - Register/memory
+	func() *Pattern {
+		p := NewPattern()
+		p.OpCode = 0b1111011
+		p.OperandType = OpTypeRegMem
+		p.GetOpCode = func(instructions []byte, i int) byte { return bits.GetBits(instructions[i], 1, 7) }
+		p.GetBytesCount = func(_ *Pattern, ins *Instruction) int {
+			if ins.Immediate != nil {
+				return ins.GetRegMemInstrByteCount() + len(ins.Immediate.Raw)
+			}
+			return ins.GetRegMemInstrByteCount()
+		}
+		p.GetWBit = func(instructions []byte, i int) bool { return bits.GetBit(instructions[i], 0) }
+		p.GetMod = func(instructions []byte, i int) byte { return bits.GetBits(instructions[i+1], 6, 2) }
+		p.GetReg = func(instructions []byte, i int) byte { return bits.GetBits(instructions[i+1], 3, 3) }
+		p.GetRM = func(instructions []byte, i int) byte { return bits.GetBits(instructions[i+1], 0, 3) }
+		p.GetDestRegister = func(ins *Instruction) string { return regFieldEnc[ins.RM][ins.WBit] }
+		p.GetSourceRegister = func(_ *Instruction) string { return "" }
+		p.GetImmediate = func(instructions []byte, i int, ins *Instruction) *ImmediateData {
+			if ins.Reg != 0b000 {
+				return nil
+			}
+			idx := i + ins.GetRegMemInstrByteCount()
+			if ins.WBit {
+				return &ImmediateData{
+					Raw:   []byte{instructions[idx], instructions[idx+1]},
+					Value: int(bits.ToUnsigned16(instructions[idx], instructions[idx+1])),
+				}
+			}
+			return &ImmediateData{
+				Raw:   []byte{instructions[idx]},
+				Value: int(bits.ToUnsigned8(instructions[idx])),
+			}
+		}
+		p.GetSorceAddr = func(_ []byte, _ int, _ *Instruction) string { return "" }
+		p.GetDestAddr = func(_ []byte, _ int, ins *Instruction) string {
+			if ins.Mod == 0b11 {
+				return ""
+			}
+			return effectiveAddrEnc[ins.Mod][ins.RM]
+		}
+		p.GetOp = func(_ *Pattern, ins *Instruction) Op { return groupF6OpEnc[ins.Reg] }
+		p.GetText = func(_ *Pattern, ins *Instruction) string {
+			if ins.Immediate != nil {
+				return fmt.Sprintf("%s, %d", ins.GetRegMemText(), ins.Immediate.Value)
+			}
+			return ins.GetRegMemText()
+		}
+		return p
+	}(),
+	// MOVS, CMPS, SCAS, LODS, STOS
+	newStringPattern(0b1010010, MOVS),
+	newStringPattern(0b1010011, CMPS),
+	newStringPattern(0b1010111, SCAS),
+	newStringPattern(0b1010110, LODS),
+	newStringPattern(0b1010101, STOS),
+	// AND, OR, XOR - Register/memory with register to either
+	NewRegMemToFromRegPattern(0b001000, AND),
+	NewRegMemToFromRegPattern(0b000010, OR),
+	NewRegMemToFromRegPattern(0b001100, XOR),
+	// AND, OR, XOR, TEST - Immediate to accumulator
+	NewImmToAccPattern(0b0010010, AND),
+	NewImmToAccPattern(0b0000110, OR),
+	NewImmToAccPattern(0b0011010, XOR),
+	NewImmToAccPattern(0b1010100, TEST),
+	// TEST - Register/memory and register
+	func() *Pattern {
+		p := NewRegMemToFromRegPattern(0b1000010, TEST)
+		p.GetOpCode = func(instructions []byte, i int) byte { return bits.GetBits(instructions[i], 1, 7) }
+		p.GetDBit = func(_ []byte, _ int) bool { return false }
+		return p
+	}(),
+	// ROL, ROR, RCL, RCR, SHL/SAL, SHR, SAR - Register/memory by 1 or by CL
+	func() *Pattern {
+		p := NewPattern()
+		p.OpCode = 0b110100
+		p.OperandType = OpTypeRegMem
+		p.GetOpCode = func(instructions []byte, i int) byte { return bits.GetBits(instructions[i], 2, 6) }
+		p.GetBytesCount = func(_ *Pattern, ins *Instruction) int {
+			return ins.GetRegMemInstrByteCount()
+		}
+		// The v bit selects a count of CL instead of 1; it sits where other opcodes keep the d bit.
+		p.GetDBit = func(instructions []byte, i int) bool { return bits.GetBit(instructions[i], 1) }
+		p.GetWBit = func(instructions []byte, i int) bool { return bits.GetBit(instructions[i], 0) }
+		p.GetMod = func(instructions []byte, i int) byte { return bits.GetBits(instructions[i+1], 6, 2) }
+		p.GetReg = func(instructions []byte, i int) byte { return bits.GetBits(instructions[i+1], 3, 3) }
+		p.GetRM = func(instructions []byte, i int) byte { return bits.GetBits(instructions[i+1], 0, 3) }
+		p.GetDestRegister = func(ins *Instruction) string { return regFieldEnc[ins.RM][ins.WBit] }
+		p.GetSourceRegister = func(ins *Instruction) string {
+			if ins.DBit {
+				return "cl"
+			}
+			return ""
+		}
+		p.GetImmediate = func(_ []byte, _ int, ins *Instruction) *ImmediateData {
+			if ins.DBit {
+				return nil
+			}
+			return &ImmediateData{Raw: []byte{1}, Value: 1}
+		}
+		p.GetSorceAddr = func(_ []byte, _ int, _ *Instruction) string { return "" }
+		p.GetDestAddr = func(_ []byte, _ int, ins *Instruction) string {
+			if ins.Mod == 0b11 {
+				return ""
+			}
+			return effectiveAddrEnc[ins.Mod][ins.RM]
+		}
+		p.GetOp = func(_ *Pattern, ins *Instruction) Op { return shiftOpEnc[ins.Reg] }
+		p.GetText = func(_ *Pattern, ins *Instruction) string {
+			if ins.DBit {
+				return fmt.Sprintf("%s, %s", ins.GetRegMemText(), ins.SourceRegister)
+			}
+			return fmt.Sprintf("%s, 1", ins.GetRegMemText())
+		}
+		return p
+	}(),
+	// DAA, DAS, AAA, AAS
+	NewSingleBytePattern(0b00100111, DAA),
+	NewSingleBytePattern(0b00101111, DAS),
+	NewSingleBytePattern(0b00110111, AAA),
+	NewSingleBytePattern(0b00111111, AAS),
+	// AAM, AAD - the second byte is the base, 10 unless hand-assembled
+	newAsciiAdjustPattern(0b11010100, AAM),
+	newAsciiAdjustPattern(0b11010101, AAD),
+	// INT - Type specified
+	func() *Pattern {
+		p := NewSingleBytePattern(0b11001101, INT)
+		p.GetBytesCount = func(_ *Pattern, _ *Instruction) int {
+			return 2
+		}
+		p.GetImmediate = func(instructions []byte, i int, _ *Instruction) *ImmediateData {
+			return &ImmediateData{
+				Raw:   []byte{instructions[i+1]},
+				Value: int(bits.ToUnsigned8(instructions[i+1])),
+			}
+		}
+		p.GetText = func(p *Pattern, ins *Instruction) string {
+			return fmt.Sprintf("%s %d", p.Op, ins.Immediate.Value)
+		}
+		return p
+	}(),
+	// INT - Type 3
+	NewSingleBytePattern(0b11001100, INT3),
+	// INTO
+	NewSingleBytePattern(0b11001110, INTO),
+	// IRET
+	NewSingleBytePattern(0b11001111, IRET),
+	// IN - Fixed port
+	newPortPattern(0b1110010, IN, false),
+	// IN - Variable port
+	newPortPattern(0b1110110, IN, true),
+	// OUT - Fixed port
+	newPortPattern(0b1110011, OUT, false),
+	// OUT - Variable port
+	newPortPattern(0b1110111, OUT, true),
+	// CLI
+	NewSingleBytePattern(0b11111010, CLI),
+	// STI
+	NewSingleBytePattern(0b11111011, STI),
+	// CLD
+	NewSingleBytePattern(0b11111100, CLD),
+	// STD
+	NewSingleBytePattern(0b11111101, STD),
+}
+
+// NewRegMemToFromRegPattern creates a Pattern for the common "register/memory with register to either"
+// encoding: a 6 bit opcode with d and w bits followed by a mod reg r/m byte.
+func NewRegMemToFromRegPattern(opCode byte, op Op) *Pattern {
+	p := NewPattern()
+	p.OpCode = opCode
+	p.Op = op
+	p.OperandType = OpTypeRegMemToFromReg
+	p.GetImmediate = func(_ []byte, _ int, _ *Instruction) *ImmediateData { return nil }
+	p.GetBytesCount = func(_ *Pattern, ins *Instruction) int {
+		return ins.GetRegMemInstrByteCount()
+	}
+	p.GetOpCode = func(instructions []byte, i int) byte { return bits.GetBits(instructions[i], 2, 6) }
+	p.GetDBit = func(instructions []byte, i int) bool { return bits.GetBit(instructions[i], 1) }
+	p.GetWBit = func(instructions []byte, i int) bool { return bits.GetBit(instructions[i], 0) }
+	p.GetMod = func(instructions []byte, i int) byte { return bits.GetBits(instructions[i+1], 6, 2) }
+	p.GetReg = func(instructions []byte, i int) byte { return bits.GetBits(instructions[i+1], 3, 3) }
+	p.GetRM = func(instructions []byte, i int) byte { return bits.GetBits(instructions[i+1], 0, 3) }
+	return p
+}
+
+// NewImmToAccPattern creates a Pattern for the "immediate to accumulator" encoding: a 7 bit opcode
+// with a w bit followed by 8 or 16 bits of data.
+func NewImmToAccPattern(opCode byte, op Op) *Pattern {
+	p := NewPattern()
+	p.OpCode = opCode
+	p.Op = op
+	p.OperandType = OpTypeImmToAcc
+	p.GetBytesCount = func(_ *Pattern, ins *Instruction) int {
+		return ins.GetImmToRegInstrByteCount()
+	}
+	p.GetOpCode = func(instructions []byte, i int) byte { return bits.GetBits(instructions[i], 1, 7) }
+	p.GetWBit = func(instructions []byte, i int) bool { return bits.GetBit(instructions[i], 0) }
+	p.GetSorceAddr = func(_ []byte, _ int, _ *Instruction) string { return "" }
+	p.GetText = func(p *Pattern, ins *Instruction) string {
+		return fmt.Sprintf("%s %s, %d", p.Op, ins.DestRegister, ins.Immediate.Value)
+	}
+	p.GetImmediate = func(instructions []byte, i int, ins *Instruction) *ImmediateData {
+		if ins.WBit {
+			return &ImmediateData{
+				Raw:      []byte{instructions[i+1], instructions[i+2]},
+				Value:    int(bits.ToSigned16(instructions[i+1], instructions[i+2])),
+				IsSigned: true,
+			}
+		}
+		return &ImmediateData{
+			Raw:      []byte{instructions[i+1]},
+			Value:    int(bits.ToSigned8(instructions[i+1])),
+			IsSigned: true,
+		}
+	}
+	return p
+}
+
+// newAsciiAdjustPattern creates a Pattern for AAM and AAD, which are followed by the base as an 8 bit immediate.
+// The base is only written out when it is not the documented 10.
+func newAsciiAdjustPattern(opCode byte, op Op) *Pattern {
+	p := NewPattern()
+	p.OpCode = opCode
+	p.Op = op
+	p.OperandType = OpTypeNone
+	p.GetOpCode = func(instructions []byte, i int) byte { return bits.GetBits(instructions[i], 0, 8) }
+	p.GetBytesCount = func(_ *Pattern, _ *Instruction) int {
+		return 2
+	}
+	p.GetSorceAddr = func(_ []byte, _ int, _ *Instruction) string { return "" }
+	p.GetImmediate = func(instructions []byte, i int, _ *Instruction) *ImmediateData {
+		return &ImmediateData{
+			Raw:   []byte{instructions[i+1]},
+			Value: int(bits.ToUnsigned8(instructions[i+1])),
+		}
+	}
+	p.GetText = func(p *Pattern, ins *Instruction) string {
+		if ins.Immediate.Value == 10 {
+			return string(p.Op)
+		}
+		return fmt.Sprintf("%s %d", p.Op, ins.Immediate.Value)
+	}
+	return p
+}
+
+// newPortPattern creates a Pattern for IN and OUT. The accumulator is the register operand; the port is
+// either an 8 bit immediate or, for the variable forms, DX.
+func newPortPattern(opCode byte, op Op, variable bool) *Pattern {
+	p := NewSingleBytePattern(opCode, op)
+	p.GetOpCode = func(instructions []byte, i int) byte { return bits.GetBits(instructions[i], 1, 7) }
+	p.GetWBit = func(instructions []byte, i int) bool { return bits.GetBit(instructions[i], 0) }
+	p.GetDestRegister = func(ins *Instruction) string { return regFieldEnc[0b000][ins.WBit] }
+	p.GetSourceRegister = func(_ *Instruction) string {
+		if variable {
+			return "dx"
+		}
+		return ""
+	}
+	if !variable {
+		p.GetBytesCount = func(_ *Pattern, _ *Instruction) int {
+			return 2
+		}
+		p.GetImmediate = func(instructions []byte, i int, _ *Instruction) *ImmediateData {
+			return &ImmediateData{
+				Raw:   []byte{instructions[i+1]},
+				Value: int(bits.ToUnsigned8(instructions[i+1])),
+			}
+		}
+	}
+	p.GetText = func(p *Pattern, ins *Instruction) string {
+		port := ins.SourceRegister
+		if !variable {
+			port = fmt.Sprintf("%d", ins.Immediate.Value)
+		}
+		if p.Op == IN {
+			return fmt.Sprintf("%s %s, %s", p.Op, ins.DestRegister, port)
+		}
+		return fmt.Sprintf("%s %s, %s", p.Op, port, ins.DestRegister)
+	}
+	return p
+}
+
+// newStringPattern creates a Pattern for a one byte string instruction whose low bit selects byte or word.
+func newStringPattern(opCode byte, op Op) *Pattern {
+	p := NewSingleBytePattern(opCode, op)
+	p.OperandType = OpTypeString
+	p.GetOpCode = func(instructions []byte, i int) byte { return bits.GetBits(instructions[i], 1, 7) }
+	p.GetWBit = func(instructions []byte, i int) bool { return bits.GetBit(instructions[i], 0) }
+	p.GetText = func(p *Pattern, ins *Instruction) string {
+		if ins.WBit {
+			return fmt.Sprintf("%sw", p.Op)
+		}
+		return fmt.Sprintf("%sb", p.Op)
+	}
+	return p
 }
@@ -0,0 +1,82 @@
+package memory
+
+import (
+	"fmt"
+	"io"
+)
+
+// Size is the size of the 8086's 20-bit physical address space (1 MB).
+const Size = 1 << 20
+
+type Memory struct {
+	data []byte
+	// readOnly lists the ROM regions, which ignore writes.
+	readOnly []region
+}
+
+// region is the half-open physical address range [start, end).
+type region struct {
+	start, end uint32
+}
+
+func NewMemory() *Memory {
+	return &Memory{
+		data: make([]byte, Size),
+	}
+}
+
+// Address converts a segment:offset pair to a physical address, wrapping at 1 MB like the 8086 does.
+func Address(segment, offset uint16) uint32 {
+	return (uint32(segment)<<4 + uint32(offset)) & (Size - 1)
+}
+
+// Read8 returns the byte at the given physical address.
+func (m *Memory) Read8(addr uint32) byte {
+	return m.data[addr&(Size-1)]
+}
+
+// Write8 stores a byte at the given physical address. Writes to ROM are dropped, as on the bus.
+func (m *Memory) Write8(addr uint32, value byte) {
+	addr &= Size - 1
+	for _, r := range m.readOnly {
+		if addr >= r.start && addr < r.end {
+			return
+		}
+	}
+	m.data[addr] = value
+}
+
+// Load copies data into memory starting at the given physical address.
+func (m *Memory) Load(addr uint32, data []byte) {
+	for i, b := range data {
+		m.Write8(addr+uint32(i), b)
+	}
+}
+
+// LoadROM copies data into memory starting at the given physical address and makes that range read-only.
+func (m *Memory) LoadROM(addr uint32, data []byte) {
+	for i, b := range data {
+		m.data[(addr+uint32(i))&(Size-1)] = b
+	}
+	m.readOnly = append(m.readOnly, region{start: addr, end: addr + uint32(len(data))})
+}
+
+// Read16 returns the little-endian word at the given physical address.
+func (m *Memory) Read16(addr uint32) uint16 {
+	return uint16(m.Read8(addr)) | uint16(m.Read8(addr+1))<<8
+}
+
+// Write16 stores a little-endian word at the given physical address.
+func (m *Memory) Write16(addr uint32, value uint16) {
+	m.Write8(addr, byte(value))
+	m.Write8(addr+1, byte(value>>8))
+}
+
+// Dump writes the bytes of the physical address range [start, end) to w.
+func (m *Memory) Dump(w io.Writer, start, end uint32) error {
+	if start > end || end > Size {
+		return fmt.Errorf("invalid memory range 0x%x-0x%x", start, end)
+	}
+	_, err := w.Write(m.data[start:end])
+	return err
+}
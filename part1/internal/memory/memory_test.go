@@ -0,0 +1,30 @@
+package memory
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestMemoryDump(t *testing.T) {
+	m := NewMemory()
+	m.Load(Address(0xb800, 0), []byte("Hi"))
+
+	var out bytes.Buffer
+	if err := m.Dump(&out, 0xb7fff, 0xb8003); err != nil {
+		t.Fatalf("Error dumping memory: %v", err)
+	}
+	if expected := []byte{0, 'H', 'i', 0}; !bytes.Equal(out.Bytes(), expected) {
+		t.Fatalf("Expected % x but got % x", expected, out.Bytes())
+	}
+
+	out.Reset()
+	if err := m.Dump(&out, 0, Size); err != nil || out.Len() != Size {
+		t.Fatalf("Expected a full dump of %d bytes but got %d (%v)", Size, out.Len(), err)
+	}
+	if err := m.Dump(&out, 0x10, 0x0f); err == nil {
+		t.Fatalf("Expected an error for a reversed range")
+	}
+	if err := m.Dump(&out, 0, Size+1); err == nil {
+		t.Fatalf("Expected an error for a range past the end of memory")
+	}
+}
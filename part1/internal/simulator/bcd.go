@@ -0,0 +1,100 @@
+package simulator
+
+import (
+	"github.com/8086-simulator/part1/internal/instruction"
+)
+
+// doDecimalAdjust executes DAA and DAS. The 8086 compares the original AL against 0x9F rather than
+// 0x99 when AF was already set, and its OF reflects the signed overflow of applying the correction.
+func (s *Simulator) doDecimalAdjust(ins *instruction.Instruction) {
+	al := s.readRegister("al")
+	oldCF := s.flags["C"]
+	oldAF := s.flags["A"]
+
+	var correction uint16
+	if al&0x0F > 9 || oldAF {
+		correction = 0x06
+	}
+	threshold := uint16(0x99)
+	if oldAF {
+		threshold = 0x9F
+	}
+	if al > threshold || oldCF {
+		correction |= 0x60
+	}
+
+	var result uint16
+	if ins.Op == instruction.DAA {
+		result = truncate(al+correction, false)
+		s.flags["O"] = ^(al^correction)&(al^result)&0x80 != 0
+	} else {
+		result = truncate(al-correction, false)
+		s.flags["O"] = (al^correction)&(al^result)&0x80 != 0
+	}
+	s.flags["A"] = correction&0x06 != 0
+	s.flags["C"] = correction&0x60 != 0
+	s.setResultFlags(result, false)
+	s.writeRegister("al", result)
+}
+
+// doAsciiAdjust executes AAA and AAS. Unlike later CPUs, the 8086 adjusts AL on its own and then
+// increments or decrements AH. PF, ZF, SF and OF come from the 8-bit correction before AL is masked.
+func (s *Simulator) doAsciiAdjust(ins *instruction.Instruction) {
+	al := s.readRegister("al")
+	ah := s.readRegister("ah")
+
+	var correction uint16
+	if al&0x0F > 9 || s.flags["A"] {
+		correction = 0x06
+	}
+
+	var result uint16
+	if ins.Op == instruction.AAA {
+		result = truncate(al+correction, false)
+		s.flags["O"] = ^(al^correction)&(al^result)&0x80 != 0
+	} else {
+		result = truncate(al-correction, false)
+		s.flags["O"] = (al^correction)&(al^result)&0x80 != 0
+	}
+	s.setResultFlags(result, false)
+
+	adjusted := correction != 0
+	s.flags["A"] = adjusted
+	s.flags["C"] = adjusted
+	if adjusted {
+		if ins.Op == instruction.AAA {
+			ah++
+		} else {
+			ah--
+		}
+		s.writeRegister("ah", truncate(ah, false))
+	}
+	s.writeRegister("al", result&0x0F)
+}
+
+// doAAM executes AAM with any base. A base of zero raises a divide error like DIV does.
+func (s *Simulator) doAAM(ins *instruction.Instruction) {
+	base := uint16(ins.Immediate.Value)
+	if base == 0 {
+		s.interrupt(DivideErrorInterrupt)
+		return
+	}
+	al := s.readRegister("al")
+	s.writeRegister("ah", al/base)
+	s.writeRegister("al", al%base)
+	s.flags["C"] = false
+	s.flags["A"] = false
+	s.flags["O"] = false
+	s.setResultFlags(al%base, false)
+}
+
+// doAAD executes AAD with any base. The 8086 performs the final step as an 8-bit ADD of AH*base to AL,
+// so CF, AF and OF come from that addition.
+func (s *Simulator) doAAD(ins *instruction.Instruction) {
+	base := uint32(ins.Immediate.Value)
+	al := uint32(s.readRegister("al"))
+	product := uint32(s.readRegister("ah")) * base & 0xFF
+	result := al + product
+	s.setArithmeticFlags(false, al, product, result, false)
+	s.writeRegister("ax", uint16(result&0xFF))
+}
@@ -0,0 +1,93 @@
+package simulator
+
+import "testing"
+
+func TestSimulatorDecimalAdjust(t *testing.T) {
+	tests := []struct {
+		name          string
+		program       []byte
+		expectedAX    uint16
+		expectedFlags string
+	}{
+		{
+			// mov al, 0x38; add al, 0x45; daa
+			name:          "daa low digit",
+			program:       []byte{0xb0, 0x38, 0x04, 0x45, 0x27},
+			expectedAX:    0x83,
+			expectedFlags: "ASO",
+		},
+		{
+			// mov al, 0x99; add al, 1; daa
+			name:          "daa decimal carry",
+			program:       []byte{0xb0, 0x99, 0x04, 0x01, 0x27},
+			expectedAX:    0x00,
+			expectedFlags: "CPAZ",
+		},
+		{
+			// mov al, 0x45; sub al, 0x38; das
+			name:          "das",
+			program:       []byte{0xb0, 0x45, 0x2c, 0x38, 0x2f},
+			expectedAX:    0x07,
+			expectedFlags: "A",
+		},
+		{
+			// mov ax, 9; add al, 8; aaa
+			name:          "aaa",
+			program:       []byte{0xb8, 0x09, 0x00, 0x04, 0x08, 0x37},
+			expectedAX:    0x0107,
+			expectedFlags: "CPA",
+		},
+		{
+			// mov ax, 0x0102; sub al, 5; aas
+			name:          "aas",
+			program:       []byte{0xb8, 0x02, 0x01, 0x2c, 0x05, 0x3f},
+			expectedAX:    0x0007,
+			expectedFlags: "CAS",
+		},
+		{
+			// mov al, 79; aam
+			name:          "aam",
+			program:       []byte{0xb0, 0x4f, 0xd4, 0x0a},
+			expectedAX:    0x0709,
+			expectedFlags: "P",
+		},
+		{
+			// mov al, 0x5f; aam 16
+			name:          "aam non-decimal base",
+			program:       []byte{0xb0, 0x5f, 0xd4, 0x10},
+			expectedAX:    0x050f,
+			expectedFlags: "P",
+		},
+		{
+			// mov ax, 0x0709; aad
+			name:          "aad",
+			program:       []byte{0xb8, 0x09, 0x07, 0xd5, 0x0a},
+			expectedAX:    0x004f,
+			expectedFlags: "",
+		},
+		{
+			// mov ax, 0x0305; aad 16
+			name:          "aad non-decimal base",
+			program:       []byte{0xb8, 0x05, 0x03, 0xd5, 0x10},
+			expectedAX:    0x0035,
+			expectedFlags: "P",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sim := NewSimulator(false)
+			sim.Load(tt.program)
+			if _, err := sim.Run(); err != nil {
+				t.Fatalf("Error running instructions: %v", err)
+			}
+
+			if ax := sim.readRegister("ax"); ax != tt.expectedAX {
+				t.Fatalf("Expected ax to be 0x%x but got 0x%x", tt.expectedAX, ax)
+			}
+			if flags := sim.printFlags(); flags != tt.expectedFlags {
+				t.Fatalf("Expected flags %s but got %s", tt.expectedFlags, flags)
+			}
+		})
+	}
+}
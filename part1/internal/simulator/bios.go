@@ -0,0 +1,174 @@
+package simulator
+
+import (
+	"errors"
+
+	"github.com/8086-simulator/part1/internal/memory"
+)
+
+// biosDataSegment holds the BIOS data area, where the video services keep their state like a real BIOS.
+const biosDataSegment uint16 = 0x0040
+
+// Offsets in the BIOS data area.
+const (
+	bdaVideoMode  uint16 = 0x49
+	bdaColumns    uint16 = 0x4A
+	bdaCursors    uint16 = 0x50
+	bdaActivePage uint16 = 0x62
+)
+
+const (
+	defaultMode        = 0x03
+	textColumns        = 80
+	textRows           = 25
+	videoPages         = 8
+	cursorShape uint16 = 0x0607
+	// clocksPerTick is one period of PIT channel 0 with the count of 65536 the BIOS programs.
+	clocksPerTick = 0x10000 * clocksPerPITTick
+	ticksPerDay   = 0x1800B0
+)
+
+// initBIOSData sets up the BIOS data area for an 80x25 color text screen and clears the screen.
+func (s *Simulator) initBIOSData() {
+	s.Memory.Write8(memory.Address(biosDataSegment, bdaVideoMode), defaultMode)
+	s.Memory.Write16(memory.Address(biosDataSegment, bdaColumns), textColumns)
+	s.Memory.Write8(memory.Address(biosDataSegment, bdaActivePage), 0)
+	for page := range uint16(videoPages) {
+		s.Memory.Write16(memory.Address(biosDataSegment, bdaCursors+page*2), 0)
+	}
+	s.clearTextScreen()
+}
+
+// int10 dispatches a video service on AH.
+func (s *Simulator) int10() error {
+	function := byte(s.readRegister("ah"))
+	switch function {
+	case 0x00:
+		// bit 7 of the mode asks to keep the screen contents
+		mode := byte(s.readRegister("al"))
+		s.Memory.Write8(memory.Address(biosDataSegment, bdaVideoMode), mode&0x7F)
+		if mode&0x80 == 0 {
+			s.clearTextScreen()
+		}
+		s.initCursors()
+	case 0x02:
+		row, column := byte(s.readRegister("dh")), byte(s.readRegister("dl"))
+		s.setCursor(byte(s.readRegister("bh")), row, column)
+	case 0x03:
+		row, column := s.cursor(byte(s.readRegister("bh")))
+		s.writeRegister("dh", uint16(row))
+		s.writeRegister("dl", uint16(column))
+		s.writeRegister("cx", cursorShape)
+	case 0x0E:
+		s.teletype(byte(s.readRegister("al")))
+	case 0x0F:
+		s.writeRegister("al", uint16(s.Memory.Read8(memory.Address(biosDataSegment, bdaVideoMode))))
+		s.writeRegister("ah", textColumns)
+		s.writeRegister("bh", uint16(s.Memory.Read8(memory.Address(biosDataSegment, bdaActivePage))))
+	default:
+		s.logger.Printf("unsupported INT 10h function 0x%02x", function)
+	}
+	return nil
+}
+
+func (s *Simulator) initCursors() {
+	for page := range byte(videoPages) {
+		s.setCursor(page, 0, 0)
+	}
+}
+
+// cursor returns the row and column of the cursor on a page.
+func (s *Simulator) cursor(page byte) (byte, byte) {
+	position := s.Memory.Read16(memory.Address(biosDataSegment, bdaCursors+uint16(page%videoPages)*2))
+	return byte(position >> 8), byte(position)
+}
+
+func (s *Simulator) setCursor(page, row, column byte) {
+	addr := memory.Address(biosDataSegment, bdaCursors+uint16(page%videoPages)*2)
+	s.Memory.Write16(addr, uint16(row)<<8|uint16(column))
+}
+
+// teletype writes a character at the cursor of the active page and moves the cursor, handling carriage
+// return, line feed, backspace and bell like the BIOS does. The character is echoed to the console too.
+func (s *Simulator) teletype(c byte) {
+	page := s.Memory.Read8(memory.Address(biosDataSegment, bdaActivePage))
+	row, column := s.cursor(page)
+	s.writeConsole(c)
+	switch c {
+	case '\r':
+		column = 0
+	case '\n':
+		row++
+	case '\b':
+		if column > 0 {
+			column--
+		}
+	case 0x07:
+	default:
+		// the cell keeps its attribute
+		_, attribute := s.textCell(int(row), int(column))
+		s.setTextCell(int(row), int(column), c, attribute)
+		column++
+		if column == textColumns {
+			column = 0
+			row++
+		}
+	}
+	if row == textRows {
+		s.scrollTextScreen()
+		row = textRows - 1
+	}
+	s.setCursor(page, row, column)
+}
+
+// int16 dispatches a keyboard service on AH, reading keys from the scripted queue.
+func (s *Simulator) int16() error {
+	function := byte(s.readRegister("ah"))
+	switch function {
+	case 0x00:
+		if len(s.keys) == 0 {
+			// a real machine would wait forever for a key, and nothing can queue one mid-run
+			return errors.New("INT 16h function 00h waits for a key with an empty queue")
+		}
+		s.writeRegister("ax", s.keys[0])
+		s.keys = s.keys[1:]
+	case 0x01:
+		if len(s.keys) == 0 {
+			s.setReturnFlag("Z", true)
+			return nil
+		}
+		s.writeRegister("ax", s.keys[0])
+		s.setReturnFlag("Z", false)
+	default:
+		s.logger.Printf("unsupported INT 16h function 0x%02x", function)
+	}
+	return nil
+}
+
+// int1A dispatches a time of day service on AH. The tick count follows the simulated clocks, one tick
+// per full period of PIT channel 0 as the BIOS programs it.
+func (s *Simulator) int1A() error {
+	function := byte(s.readRegister("ah"))
+	switch function {
+	case 0x00:
+		ticks := s.ticks()
+		s.writeRegister("cx", uint16(ticks>>16))
+		s.writeRegister("dx", uint16(ticks))
+		s.writeRegister("al", 0)
+	case 0x01:
+		set := uint32(s.readRegister("cx"))<<16 | uint32(s.readRegister("dx"))
+		s.tickOffset = int(set) - s.totalClocks/clocksPerTick
+	default:
+		s.logger.Printf("unsupported INT 1Ah function 0x%02x", function)
+	}
+	return nil
+}
+
+// ticks returns the ticks since midnight.
+func (s *Simulator) ticks() uint32 {
+	ticks := (s.totalClocks/clocksPerTick + s.tickOffset) % ticksPerDay
+	if ticks < 0 {
+		ticks += ticksPerDay
+	}
+	return uint32(ticks)
+}
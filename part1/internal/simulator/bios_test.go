@@ -0,0 +1,143 @@
+package simulator
+
+import (
+	"io"
+	"log"
+	"strings"
+	"testing"
+)
+
+func TestSimulatorBIOS(t *testing.T) {
+	exit := []byte{
+		0xb8, 0x00, 0x4c, // mov ax, 0x4c00
+		0xcd, 0x21, // int 33
+	}
+
+	tests := []struct {
+		name           string
+		program        []byte
+		keys           string
+		clocks         int
+		expectedOutput string
+		expectedError  bool
+		expected       map[string]uint16
+	}{
+		{
+			name: "teletype moves the cursor",
+			program: append([]byte{
+				0xb8, 0x48, 0x0e, // mov ax, 0x0e48
+				0xcd, 0x10, // int 16
+				0xb0, 0x69, // mov al, 'i'
+				0xcd, 0x10, // int 16
+				0xb0, 0x0d, // mov al, 13
+				0xcd, 0x10, // int 16
+				0xb0, 0x0a, // mov al, 10
+				0xcd, 0x10, // int 16
+				0xb0, 0x21, // mov al, '!'
+				0xcd, 0x10, // int 16
+				0xb4, 0x03, // mov ah, 3
+				0xb7, 0x00, // mov bh, 0
+				0xcd, 0x10, // int 16
+				0x89, 0xd6, // mov si, dx
+			}, exit...),
+			expectedOutput: "Hi\r\n!",
+			expected:       map[string]uint16{"si": 0x0101, "cx": cursorShape},
+		},
+		{
+			name: "set cursor and video mode",
+			program: append([]byte{
+				0xb8, 0x01, 0x00, // mov ax, 1
+				0xcd, 0x10, // int 16
+				0xb4, 0x02, // mov ah, 2
+				0xb7, 0x00, // mov bh, 0
+				0xba, 0x0a, 0x05, // mov dx, 0x050a
+				0xcd, 0x10, // int 16
+				0xba, 0x00, 0x00, // mov dx, 0
+				0xb4, 0x03, // mov ah, 3
+				0xcd, 0x10, // int 16
+				0xb4, 0x0f, // mov ah, 15
+				0xcd, 0x10, // int 16
+				0x89, 0xc6, // mov si, ax
+			}, exit...),
+			expected: map[string]uint16{"si": 0x5001, "dx": 0x050a},
+		},
+		{
+			name: "read and check keys",
+			program: append([]byte{
+				0xb4, 0x01, // mov ah, 1
+				0xcd, 0x16, // int 22
+				0x89, 0xc7, // mov di, ax
+				0xb4, 0x00, // mov ah, 0
+				0xcd, 0x16, // int 22
+				0x89, 0xc6, // mov si, ax
+				0xb4, 0x01, // mov ah, 1
+				0xcd, 0x16, // int 22
+				0x9c, // pushf
+				0x5b, // pop bx
+			}, exit...),
+			keys: "a",
+			// the key stays queued after function 01h, and ZF reports the empty queue afterwards
+			expected: map[string]uint16{"di": 0x1e61, "si": 0x1e61, "bx": 0xf042},
+		},
+		{
+			name: "read a key from an empty queue",
+			program: append([]byte{
+				0xb4, 0x00, // mov ah, 0
+				0xcd, 0x16, // int 22
+			}, exit...),
+			expectedError: true,
+		},
+		{
+			name: "tick count",
+			program: append([]byte{
+				0xb4, 0x00, // mov ah, 0
+				0xcd, 0x1a, // int 26
+				0x89, 0xd6, // mov si, dx
+				0xb4, 0x01, // mov ah, 1
+				0xb9, 0x01, 0x00, // mov cx, 1
+				0xba, 0x02, 0x00, // mov dx, 2
+				0xcd, 0x1a, // int 26
+				0xb4, 0x00, // mov ah, 0
+				0xcd, 0x1a, // int 26
+			}, exit...),
+			clocks:   3*clocksPerTick + 100,
+			expected: map[string]uint16{"si": 3, "cx": 1, "dx": 2},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sim := NewSimulator(false)
+			var stdout strings.Builder
+			sim.SetConsole(strings.NewReader(""), &stdout)
+			sim.SetLogger(log.New(io.Discard, "", 0))
+			sim.QueueKeys(tt.keys)
+			if err := sim.LoadCOM(tt.program, ""); err != nil {
+				t.Fatalf("Error loading program: %v", err)
+			}
+			sim.totalClocks = tt.clocks
+			_, err := sim.Run()
+			if tt.expectedError {
+				if err == nil || sim.Terminated() {
+					t.Fatalf("Expected the run to fail but got error %v, terminated %t", err, sim.Terminated())
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Error running instructions: %v", err)
+			}
+
+			if !sim.Terminated() {
+				t.Fatalf("Expected the program to terminate")
+			}
+			if stdout.String() != tt.expectedOutput {
+				t.Fatalf("Expected output %q but got %q", tt.expectedOutput, stdout.String())
+			}
+			for register, expected := range tt.expected {
+				if got := sim.readRegister(register); got != expected {
+					t.Fatalf("Expected %s to be 0x%x but got 0x%x", register, expected, got)
+				}
+			}
+		})
+	}
+}
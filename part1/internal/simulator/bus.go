@@ -0,0 +1,68 @@
+package simulator
+
+import (
+	"github.com/8086-simulator/part1/internal/bits"
+	"github.com/8086-simulator/part1/internal/memory"
+)
+
+// BusStats counts the data transfers made by executed instructions. Instruction fetches are not included.
+type BusStats struct {
+	// Accesses is the number of byte and word reads and writes.
+	Accesses int
+	// Transfers is the number of bus cycles used. A word at an odd address on the 8086, or any word
+	// on the 8088, takes two.
+	Transfers int
+	// UnalignedWords is the number of word accesses at an odd address.
+	UnalignedWords int
+}
+
+func (b *BusStats) add(other BusStats) {
+	b.Accesses += other.Accesses
+	b.Transfers += other.Transfers
+	b.UnalignedWords += other.UnalignedWords
+}
+
+// extraCycles is the number of bus cycles spent on top of one per access.
+func (b BusStats) extraCycles() int {
+	return b.Transfers - b.Accesses
+}
+
+// BusStats returns the data transfers of all instructions executed so far.
+func (s *Simulator) BusStats() BusStats {
+	return s.bus
+}
+
+// busRead reads a byte or a little-endian word at segment:offset; word offsets wrap within the segment.
+func (s *Simulator) busRead(segment, offset uint16, wide bool) uint16 {
+	s.countAccess(memory.Address(segment, offset), wide)
+	low := s.Memory.Read8(memory.Address(segment, offset))
+	if !wide {
+		return bits.ToUnsigned8(low)
+	}
+	high := s.Memory.Read8(memory.Address(segment, offset+1))
+	return bits.ToUnsigned16(low, high)
+}
+
+func (s *Simulator) busWrite(segment, offset uint16, wide bool, value uint16) {
+	s.countAccess(memory.Address(segment, offset), wide)
+	s.Memory.Write8(memory.Address(segment, offset), byte(value))
+	if wide {
+		s.Memory.Write8(memory.Address(segment, offset+1), byte(value>>8))
+	}
+}
+
+// countAccess records the bus cycles of one access made by the current instruction.
+func (s *Simulator) countAccess(addr uint32, wide bool) {
+	transfers := 1
+	if wide {
+		if addr%2 == 1 {
+			s.instructionBus.UnalignedWords++
+			transfers = 2
+		}
+		if s.cpuModel == CPU8088 {
+			transfers = 2
+		}
+	}
+	s.instructionBus.Accesses++
+	s.instructionBus.Transfers += transfers
+}
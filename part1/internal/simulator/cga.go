@@ -0,0 +1,105 @@
+package simulator
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/8086-simulator/part1/internal/memory"
+)
+
+const (
+	// textSegment is the CGA text buffer; each cell is a character followed by its attribute.
+	textSegment uint16 = 0xB800
+	// defaultAttribute is light gray on black.
+	defaultAttribute = 0x07
+)
+
+// cp437 maps the IBM PC character set to Unicode. Character 0 shows as a space.
+var cp437 = []rune(" ☺☻♥♦♣♠•◘○◙♂♀♪♫☼►◄↕‼¶§▬↨↑↓→←∟↔▲▼" +
+	" !\"#$%&'()*+,-./0123456789:;<=>?@ABCDEFGHIJKLMNOPQRSTUVWXYZ[\\]^_`abcdefghijklmnopqrstuvwxyz{|}~⌂" +
+	"ÇüéâäàåçêëèïîìÄÅÉæÆôöòûùÿÖÜ¢£¥₧ƒáíóúñÑªº¿⌐¬½¼¡«»░▒▓│┤╡╢╖╕╣║╗╝╜╛┐└┴┬├─┼╞╟╚╔╩╦╠═╬╧╨╤╥╙╘╒╓╫╪┘┌█▄▌▐▀" +
+	"αßΓπΣσµτΦΘΩδ∞φε∩≡±≥≤⌠⌡÷≈°∙·√ⁿ²■\u00a0")
+
+// ansiColors maps the CGA color order (black, blue, green, cyan, red, magenta, brown, gray) to ANSI's.
+var ansiColors = [8]int{0, 4, 2, 6, 1, 5, 3, 7}
+
+// textCell returns the character and attribute at a row and column of the text screen.
+func (s *Simulator) textCell(row, column int) (byte, byte) {
+	offset := uint16((row*textColumns + column) * 2)
+	return s.Memory.Read8(memory.Address(textSegment, offset)), s.Memory.Read8(memory.Address(textSegment, offset+1))
+}
+
+func (s *Simulator) setTextCell(row, column int, c, attribute byte) {
+	offset := uint16((row*textColumns + column) * 2)
+	s.Memory.Write8(memory.Address(textSegment, offset), c)
+	s.Memory.Write8(memory.Address(textSegment, offset+1), attribute)
+}
+
+// clearTextScreen fills the screen with spaces in the default attribute.
+func (s *Simulator) clearTextScreen() {
+	for row := range textRows {
+		for column := range textColumns {
+			s.setTextCell(row, column, ' ', defaultAttribute)
+		}
+	}
+}
+
+// scrollTextScreen moves every row up by one and blanks the last row.
+func (s *Simulator) scrollTextScreen() {
+	for row := 1; row < textRows; row++ {
+		for column := range textColumns {
+			c, attribute := s.textCell(row, column)
+			s.setTextCell(row-1, column, c, attribute)
+		}
+	}
+	for column := range textColumns {
+		s.setTextCell(textRows-1, column, ' ', defaultAttribute)
+	}
+}
+
+// TextScreen returns the 80x25 text screen at B800:0000 as plain text, one line per row with trailing
+// spaces removed.
+func (s *Simulator) TextScreen() string {
+	var b strings.Builder
+	for row := range textRows {
+		var line strings.Builder
+		for column := range textColumns {
+			c, _ := s.textCell(row, column)
+			line.WriteRune(cp437[c])
+		}
+		b.WriteString(strings.TrimRight(line.String(), " "))
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+// RenderTextScreen writes the text screen to a terminal, using ANSI escape codes for the attribute
+// colors. The blink bit is ignored.
+func (s *Simulator) RenderTextScreen(w io.Writer) error {
+	var b strings.Builder
+	for row := range textRows {
+		current := -1
+		for column := range textColumns {
+			c, attribute := s.textCell(row, column)
+			if int(attribute) != current {
+				b.WriteString(ansiAttribute(attribute))
+				current = int(attribute)
+			}
+			b.WriteRune(cp437[c])
+		}
+		b.WriteString("\x1b[0m\n")
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// ansiAttribute returns the escape sequence selecting a CGA attribute's foreground and background.
+func ansiAttribute(attribute byte) string {
+	foreground := 30 + ansiColors[attribute&0x07]
+	if attribute&0x08 != 0 {
+		foreground += 60
+	}
+	background := 40 + ansiColors[(attribute>>4)&0x07]
+	return fmt.Sprintf("\x1b[%d;%dm", foreground, background)
+}
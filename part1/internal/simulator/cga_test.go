@@ -0,0 +1,57 @@
+package simulator
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestSimulatorTextScreen(t *testing.T) {
+	sim := NewSimulator(false)
+	sim.Load([]byte{
+		0xb8, 0x00, 0xb8, // mov ax, 0xb800
+		0x8e, 0xc0, // mov es, ax
+		0x26, 0xc7, 0x06, 0x00, 0x00, 0x48, 0x1f, // mov word es:[0], 0x1f48
+		0x26, 0xc7, 0x06, 0x02, 0x00, 0xc4, 0x07, // mov word es:[2], 0x07c4
+		0x26, 0xc7, 0x06, 0xa0, 0x00, 0x69, 0x07, // mov word es:[160], 0x0769
+		0xf4, // hlt
+	})
+	if _, err := sim.Run(); err != nil {
+		t.Fatalf("Error running instructions: %v", err)
+	}
+
+	lines := strings.Split(sim.TextScreen(), "\n")
+	if len(lines) != textRows+1 || lines[0] != "H─" || lines[1] != "i" || lines[2] != "" {
+		t.Fatalf("Expected the screen to start with H─ and i but got %q", lines[:3])
+	}
+
+	var rendered strings.Builder
+	if err := sim.RenderTextScreen(&rendered); err != nil {
+		t.Fatalf("Error rendering: %v", err)
+	}
+	// bright white on blue, then light gray on black
+	expected := "\x1b[97;44mH\x1b[37;40m─\x1b[30;40m "
+	if !strings.HasPrefix(rendered.String(), expected) {
+		t.Fatalf("Expected the rendering to start with %q but got %q", expected, rendered.String()[:len(expected)])
+	}
+}
+
+func TestSimulatorTeletypeScrolls(t *testing.T) {
+	sim := NewSimulator(false)
+	sim.SetConsole(strings.NewReader(""), io.Discard)
+	sim.installHandlers()
+	for i := range textRows + 1 {
+		for _, c := range []byte(fmt.Sprintf("%d\r\n", i)) {
+			sim.teletype(c)
+		}
+	}
+
+	lines := strings.Split(sim.TextScreen(), "\n")
+	if lines[0] != "2" || lines[textRows-2] != "25" || lines[textRows-1] != "" {
+		t.Fatalf("Expected rows 2 to 25 after scrolling but got %q", lines)
+	}
+	if row, column := sim.cursor(0); row != textRows-1 || column != 0 {
+		t.Fatalf("Expected the cursor at the start of the last row but got %d,%d", row, column)
+	}
+}
@@ -0,0 +1,375 @@
+package simulator
+
+import (
+	"fmt"
+
+	"github.com/8086-simulator/part1/internal/instruction"
+	"github.com/8086-simulator/part1/internal/memory"
+)
+
+// CPUModel selects the bus width used for clock estimates.
+type CPUModel int
+
+const (
+	// CPU8086 has a 16-bit data bus: word transfers cost one bus cycle unless the address is odd.
+	CPU8086 CPUModel = iota
+	// CPU8088 has an 8-bit data bus: every word transfer takes two bus cycles.
+	CPU8088
+)
+
+const (
+	// wordPenaltyClocks is the cost of the extra bus cycle needed by a split word transfer.
+	wordPenaltyClocks = 4
+	// hardwareInterruptClocks is the cost of acknowledging an external interrupt and entering its handler.
+	hardwareInterruptClocks = 61
+)
+
+// clockEstimate is the estimated cost of one instruction, split the way the 8086 manual tables list it.
+type clockEstimate struct {
+	base    int
+	ea      int
+	penalty int
+	// queue is the time spent waiting for the instruction to be fetched, only used by TimingPrefetch.
+	queue int
+}
+
+func (c clockEstimate) total() int {
+	return c.base + c.ea + c.penalty + c.queue
+}
+
+// breakdown returns the parts of the estimate, e.g. " (8 + 6ea + 4p)", when there is more than a base cost.
+func (c clockEstimate) breakdown() string {
+	if c.ea == 0 && c.penalty == 0 && c.queue == 0 {
+		return ""
+	}
+	text := fmt.Sprintf(" (%d", c.base)
+	if c.ea != 0 {
+		text += fmt.Sprintf(" + %dea", c.ea)
+	}
+	if c.penalty != 0 {
+		text += fmt.Sprintf(" + %dp", c.penalty)
+	}
+	if c.queue != 0 {
+		text += fmt.Sprintf(" + %dq", c.queue)
+	}
+	return text + ")"
+}
+
+// EstimateClocks selects the CPU whose timings are used and adds each instruction's clocks and the
+// running total to the trace.
+func (s *Simulator) EstimateClocks(model CPUModel) {
+	s.estimateClocks = true
+	s.cpuModel = model
+}
+
+// Clocks returns the estimated number of clocks spent by the instructions executed so far.
+func (s *Simulator) Clocks() int {
+	return s.totalClocks
+}
+
+// instructionClocks estimates an instruction from its encoding and the registers before it executes.
+// Costs that depend on the outcome, such as a taken branch, repeated string iterations or the extra
+// bus cycles of word transfers, are added afterwards by outcomeClocks.
+func (s *Simulator) instructionClocks(ins *instruction.Instruction) clockEstimate {
+	hasMem := s.hasMemoryOperand(ins)
+	memIsDest := hasMem && ins.DestAddr != ""
+	immediate := ins.OperandType == instruction.OpTypeImmToReg || ins.OperandType == instruction.OpTypeImmToAcc
+
+	var base int
+	switch ins.Op {
+	case instruction.MOV:
+		switch {
+		case ins.OperandType == instruction.OpTypeMemToFromAcc:
+			base = 10
+		case immediate && hasMem:
+			base = 10
+		case immediate:
+			base = 4
+		case memIsDest:
+			base = 9
+		case hasMem:
+			base = 8
+		default:
+			base = 2
+		}
+	case instruction.ADD, instruction.SUB, instruction.AND, instruction.OR, instruction.XOR:
+		switch {
+		case immediate && hasMem:
+			base = 17
+		case immediate:
+			base = 4
+		case memIsDest:
+			base = 16
+		case hasMem:
+			base = 9
+		default:
+			base = 3
+		}
+	case instruction.CMP:
+		switch {
+		case immediate && hasMem:
+			base = 10
+		case immediate:
+			base = 4
+		case hasMem:
+			base = 9
+		default:
+			base = 3
+		}
+	case instruction.TEST:
+		switch {
+		case ins.OperandType == instruction.OpTypeImmToAcc:
+			base = 4
+		case ins.Immediate != nil && hasMem:
+			base = 11
+		case ins.Immediate != nil:
+			base = 5
+		case hasMem:
+			base = 9
+		default:
+			base = 3
+		}
+	case instruction.NOT, instruction.NEG:
+		if hasMem {
+			base = 16
+		} else {
+			base = 3
+		}
+	case instruction.INC, instruction.DEC:
+		switch {
+		case ins.OperandType == instruction.OpTypeRegister:
+			base = 2
+		case hasMem:
+			base = 15
+		default:
+			base = 3
+		}
+	case instruction.SHL, instruction.SHR, instruction.SAR, instruction.ROL, instruction.ROR,
+		instruction.RCL, instruction.RCR:
+		byCL := ins.SourceRegister == "cl"
+		perBit := 0
+		if byCL {
+			perBit = 4 * int(s.readRegister("cl"))
+		}
+		switch {
+		case hasMem && byCL:
+			base = 20 + perBit
+		case hasMem:
+			base = 15
+		case byCL:
+			base = 8 + perBit
+		default:
+			base = 2
+		}
+	case instruction.MUL, instruction.IMUL, instruction.DIV, instruction.IDIV:
+		base = multiplyClocks[ins.Op][ins.WBit]
+		if hasMem {
+			base += 6
+		}
+	case instruction.JNZ, instruction.JE, instruction.JL, instruction.JLE, instruction.JB, instruction.JBE,
+		instruction.JP, instruction.JO, instruction.JS, instruction.JNE, instruction.JNL, instruction.JG,
+		instruction.JNB, instruction.JA, instruction.JNP, instruction.JNO, instruction.JNS,
+		instruction.LOOP, instruction.LOOPNZ:
+		base = 4
+		if ins.Op == instruction.LOOP || ins.Op == instruction.LOOPNZ {
+			base = 5
+		}
+	case instruction.LOOPZ, instruction.JCXZ:
+		base = 6
+	case instruction.JMP:
+		switch {
+		case ins.OperandType == instruction.OpTypeJump:
+			base = 15
+		case hasMem:
+			base = 18
+		default:
+			base = 11
+		}
+	case instruction.JMPF:
+		if hasMem {
+			base = 24
+		} else {
+			base = 15
+		}
+	case instruction.CALL:
+		switch {
+		case ins.OperandType == instruction.OpTypeJump:
+			base = 19
+		case hasMem:
+			base = 21
+		default:
+			base = 16
+		}
+	case instruction.CALLF:
+		if hasMem {
+			base = 37
+		} else {
+			base = 28
+		}
+	case instruction.RET:
+		base = 8
+		if ins.Immediate != nil {
+			base = 12
+		}
+	case instruction.RETF:
+		base = 18
+		if ins.Immediate != nil {
+			base = 17
+		}
+	case instruction.PUSH:
+		switch {
+		case hasMem:
+			base = 16
+		case isSegmentRegister(ins.DestRegister):
+			base = 10
+		default:
+			base = 11
+		}
+	case instruction.POP:
+		if hasMem {
+			base = 17
+		} else {
+			base = 8
+		}
+	case instruction.PUSHF:
+		base = 10
+	case instruction.POPF:
+		base = 8
+	case instruction.MOVS, instruction.CMPS, instruction.SCAS, instruction.LODS, instruction.STOS:
+		base = stringClocks[ins.Op].single
+		if ins.Rep != "" {
+			base = 9
+		}
+	case instruction.DAA, instruction.DAS, instruction.AAA, instruction.AAS:
+		base = 4
+	case instruction.AAM:
+		base = 83
+	case instruction.AAD:
+		base = 60
+	case instruction.INT:
+		base = 51
+	case instruction.INT3:
+		base = 52
+	case instruction.INTO:
+		base = 4
+	case instruction.IRET:
+		base = 24
+	case instruction.IN, instruction.OUT:
+		base = 10
+		if ins.Immediate == nil {
+			base = 8
+		}
+	case instruction.CLD, instruction.STD, instruction.CLI, instruction.STI, instruction.HLT:
+		base = 2
+	}
+
+	estimate := clockEstimate{base: base}
+	if hasMem {
+		estimate.ea = effectiveAddressClocks(ins)
+	}
+	return estimate
+}
+
+// outcomeClocks adds the clocks that depend on how the instruction executed. With TimingPrefetch it
+// also charges the wait for the instruction's bytes, starting at the physical address start.
+func (s *Simulator) outcomeClocks(ins *instruction.Instruction, start uint32, estimate *clockEstimate) {
+	estimate.penalty = s.instructionBus.extraCycles() * wordPenaltyClocks
+	estimate.base += s.branchClocks(ins)
+	if s.timingModel == TimingPrefetch {
+		estimate.queue = s.waitForInstruction(start, ins.Size)
+		s.prefetchDuring(estimate.total()-estimate.queue, s.instructionBus.Transfers)
+		if s.transferredControl(ins) {
+			s.queue.reset()
+		}
+	}
+}
+
+// transferredControl reports whether the instruction loaded CS:IP with something other than the address
+// following it, which makes the BIU discard the prefetched bytes.
+func (s *Simulator) transferredControl(ins *instruction.Instruction) bool {
+	switch ins.Op {
+	case instruction.CALL, instruction.CALLF, instruction.JMP, instruction.JMPF, instruction.RET, instruction.RETF,
+		instruction.INT, instruction.INT3, instruction.IRET:
+		return true
+	}
+	return s.branchTaken || memory.Address(s.readRegister("cs"), s.readRegister("ip")) != s.queue.next
+}
+
+// branchClocks returns the extra clocks of a taken branch, a taken INTO or the repetitions of a REP string instruction.
+func (s *Simulator) branchClocks(ins *instruction.Instruction) int {
+	switch {
+	case ins.OperandType == instruction.OpTypeJump && s.branchTaken:
+		if ins.Op == instruction.LOOPNZ {
+			return 14
+		}
+		return 12
+	case ins.OperandType == instruction.OpTypeString && ins.Rep != "":
+		return stringClocks[ins.Op].repeated * s.repetitions
+	case ins.Op == instruction.INTO && s.flags["O"]:
+		return 49
+	}
+	return 0
+}
+
+// hasMemoryOperand reports whether the instruction has an operand encoded in its mod r/m byte that is in memory.
+func (s *Simulator) hasMemoryOperand(ins *instruction.Instruction) bool {
+	switch ins.OperandType {
+	case instruction.OpTypeRegMemToFromReg:
+		return ins.DestAddr != "" || ins.SourceAddr != ""
+	case instruction.OpTypeImmToReg, instruction.OpTypeRegMem:
+		return ins.DestAddr != ""
+	}
+	return false
+}
+
+// effectiveAddressClocks returns the cost of computing a memory operand's address.
+func effectiveAddressClocks(ins *instruction.Instruction) int {
+	var clocks int
+	switch ins.RM {
+	case 0b000, 0b011: // bx + si, bp + di
+		clocks = 7
+	case 0b001, 0b010: // bx + di, bp + si
+		clocks = 8
+	case 0b110:
+		if ins.Mod == 0b00 {
+			clocks = 6 // direct address
+		} else {
+			clocks = 5
+		}
+	default:
+		clocks = 5
+	}
+	if ins.Mod == 0b01 || ins.Mod == 0b10 {
+		clocks += 4
+	}
+	if ins.SegmentOverride != "" {
+		clocks += 2
+	}
+	return clocks
+}
+
+// multiplyClocks holds the fastest register form of each multiply and divide, by operand width.
+// The manual gives a range because the microcode loop depends on the operands.
+var multiplyClocks = map[instruction.Op]map[bool]int{
+	instruction.MUL:  {false: 70, true: 118},
+	instruction.IMUL: {false: 80, true: 128},
+	instruction.DIV:  {false: 80, true: 144},
+	instruction.IDIV: {false: 101, true: 165},
+}
+
+// stringClocks holds the cost of a string instruction on its own and per repetition under a REP prefix.
+var stringClocks = map[instruction.Op]struct{ single, repeated int }{
+	instruction.MOVS: {18, 17},
+	instruction.CMPS: {22, 22},
+	instruction.SCAS: {15, 15},
+	instruction.LODS: {12, 13},
+	instruction.STOS: {11, 10},
+}
+
+func isSegmentRegister(name string) bool {
+	switch name {
+	case "es", "cs", "ss", "ds":
+		return true
+	}
+	return false
+}
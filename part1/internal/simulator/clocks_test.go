@@ -0,0 +1,236 @@
+package simulator
+
+import "testing"
+
+// clocksProgram mixes register, memory and unaligned word operands.
+var clocksProgram = []byte{
+	0xbb, 0xe8, 0x03, // mov bx, 1000
+	0x89, 0xd9, // mov cx, bx
+	0x8b, 0x17, // mov dx, [bx]
+	0x8b, 0x4e, 0x00, // mov cx, [bp + 0] (reads the first program word at ss:0)
+	0x01, 0x48, 0x03, // add [bx + si + 3], cx
+}
+
+func TestSimulatorClocks8086(t *testing.T) {
+	sim := NewSimulator(true)
+	sim.EstimateClocks(CPU8086)
+	expectedLogs := []string{
+		"mov bx, 1000 ; Clocks: +4 = 4 | bx:0x0->0x3e8 ip:0x0->0x3",
+		"mov cx, bx ; Clocks: +2 = 6 | cx:0x0->0x3e8 ip:0x3->0x5",
+		"mov dx, [bx] ; Clocks: +13 = 19 (8 + 5ea) | ip:0x5->0x7",
+		"mov cx, [bp + 0] ; Clocks: +17 = 36 (8 + 9ea) | cx:0x3e8->0xe8bb ip:0x7->0xa",
+		"add [bx + si + 3], cx ; Clocks: +35 = 71 (16 + 11ea + 8p) | ip:0xa->0xd flags:->PS",
+	}
+
+	sim.Load(clocksProgram)
+	results, err := sim.Run()
+	if err != nil {
+		t.Fatalf("Error running instructions: %v", err)
+	}
+
+	if len(results) != len(expectedLogs) {
+		t.Fatalf("Expected %d instructions but got %d", len(expectedLogs), len(results))
+	}
+	for i, result := range results {
+		if result.Text != expectedLogs[i] {
+			t.Fatalf("\nExpected instruction: %s\n                 Got: %s", expectedLogs[i], result.Text)
+		}
+	}
+	if sim.Clocks() != 71 {
+		t.Fatalf("Expected 71 clocks but got %d", sim.Clocks())
+	}
+}
+
+func TestSimulatorClocks(t *testing.T) {
+	tests := []struct {
+		name     string
+		model    CPUModel
+		program  []byte
+		expected int
+	}{
+		{
+			// every word transfer pays the penalty on the 8088
+			name:     "8088 word transfers",
+			model:    CPU8088,
+			program:  clocksProgram,
+			expected: 79,
+		},
+		{
+			// mov ax, es:[bx]
+			name:     "segment override",
+			model:    CPU8086,
+			program:  []byte{0x26, 0x8b, 0x07},
+			expected: 15,
+		},
+		{
+			// mov cx, 3; l: loop l
+			name:     "loop taken and not taken",
+			model:    CPU8086,
+			program:  []byte{0xb9, 0x03, 0x00, 0xe2, 0xfe},
+			expected: 43,
+		},
+		{
+			// mov cx, 3; rep movsb
+			name:     "rep movsb",
+			model:    CPU8086,
+			program:  []byte{0xb9, 0x03, 0x00, 0xf3, 0xa4},
+			expected: 64,
+		},
+		{
+			// mov cl, 4; shl ax, cl
+			name:     "shift by cl",
+			model:    CPU8086,
+			program:  []byte{0xb1, 0x04, 0xd3, 0xe0},
+			expected: 28,
+		},
+		{
+			// mov [256], ax; mov al, [257]; mov ax, [257] (unaligned)
+			name:     "accumulator to and from memory",
+			model:    CPU8086,
+			program:  []byte{0xa3, 0x00, 0x01, 0xa0, 0x01, 0x01, 0xa1, 0x01, 0x01},
+			expected: 34,
+		},
+		{
+			// mov cx, 3; l: dec cx; jnz l; inc ax
+			name:     "inc and dec word register",
+			model:    CPU8086,
+			program:  []byte{0xb9, 0x03, 0x00, 0x49, 0x75, 0xfd, 0x40},
+			expected: 48,
+		},
+		{
+			// mov bl, 3; mul bl
+			name:     "mul",
+			model:    CPU8086,
+			program:  []byte{0xb3, 0x03, 0xf6, 0xe3},
+			expected: 74,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sim := NewSimulator(false)
+			sim.EstimateClocks(tt.model)
+			sim.Load(tt.program)
+			if _, err := sim.Run(); err != nil {
+				t.Fatalf("Error running instructions: %v", err)
+			}
+
+			if sim.Clocks() != tt.expected {
+				t.Fatalf("Expected %d clocks but got %d", tt.expected, sim.Clocks())
+			}
+		})
+	}
+}
+
+func TestSimulatorBusStats(t *testing.T) {
+	tests := []struct {
+		name           string
+		model          CPUModel
+		program        []byte
+		expectedClocks int
+		expectedStats  BusStats
+	}{
+		{
+			name:           "unaligned operand",
+			model:          CPU8086,
+			program:        clocksProgram,
+			expectedClocks: 71,
+			expectedStats:  BusStats{Accesses: 4, Transfers: 6, UnalignedWords: 2},
+		},
+		{
+			// mov sp, 0x101; push ax
+			name:           "unaligned stack",
+			model:          CPU8086,
+			program:        []byte{0xbc, 0x01, 0x01, 0x50},
+			expectedClocks: 19,
+			expectedStats:  BusStats{Accesses: 1, Transfers: 2, UnalignedWords: 1},
+		},
+		{
+			// mov cx, 2; mov di, 0x100; rep movsw
+			name:           "8088 string words",
+			model:          CPU8088,
+			program:        []byte{0xb9, 0x02, 0x00, 0xbf, 0x00, 0x01, 0xf3, 0xa5},
+			expectedClocks: 67,
+			expectedStats:  BusStats{Accesses: 4, Transfers: 8},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sim := NewSimulator(false)
+			sim.EstimateClocks(tt.model)
+			sim.Load(tt.program)
+			if _, err := sim.Run(); err != nil {
+				t.Fatalf("Error running instructions: %v", err)
+			}
+
+			if sim.Clocks() != tt.expectedClocks {
+				t.Fatalf("Expected %d clocks but got %d", tt.expectedClocks, sim.Clocks())
+			}
+			if stats := sim.BusStats(); stats != tt.expectedStats {
+				t.Fatalf("Expected bus stats %+v but got %+v", tt.expectedStats, stats)
+			}
+		})
+	}
+}
+
+func TestSimulatorPrefetchClocks(t *testing.T) {
+	program := []byte{
+		0xb8, 0x01, 0x00, // mov ax, 1
+		0xbb, 0x02, 0x00, // mov bx, 2
+		0x01, 0xd8, // add ax, bx
+	}
+	tests := []struct {
+		name     string
+		model    CPUModel
+		timing   TimingModel
+		program  []byte
+		expected int
+	}{
+		{
+			name:     "table",
+			model:    CPU8086,
+			timing:   TimingTable,
+			program:  program,
+			expected: 11,
+		},
+		{
+			name:     "8086 queue",
+			model:    CPU8086,
+			timing:   TimingPrefetch,
+			program:  program,
+			expected: 19,
+		},
+		{
+			name:     "8088 queue",
+			model:    CPU8088,
+			timing:   TimingPrefetch,
+			program:  program,
+			expected: 35,
+		},
+		{
+			// jmp $+2; mov ax, 1 - the jump flushes the queue even though it lands on the next instruction
+			name:     "jump flushes queue",
+			model:    CPU8086,
+			timing:   TimingPrefetch,
+			program:  []byte{0xeb, 0x00, 0xb8, 0x01, 0x00},
+			expected: 31,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sim := NewSimulator(false)
+			sim.EstimateClocks(tt.model)
+			sim.SetTimingModel(tt.timing)
+			sim.Load(tt.program)
+			if _, err := sim.Run(); err != nil {
+				t.Fatalf("Error running instructions: %v", err)
+			}
+
+			if sim.Clocks() != tt.expected {
+				t.Fatalf("Expected %d clocks but got %d", tt.expected, sim.Clocks())
+			}
+		})
+	}
+}
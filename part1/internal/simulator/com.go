@@ -0,0 +1,70 @@
+package simulator
+
+import (
+	"fmt"
+
+	"github.com/8086-simulator/part1/internal/memory"
+)
+
+// ProgramSegment is where the loaders place the Program Segment Prefix of the program they load.
+const ProgramSegment uint16 = 0x1000
+
+const (
+	pspSize = 0x100
+	// memoryTopSegment is the first segment past conventional memory, stored in the PSP.
+	memoryTopSegment uint16 = 0xA000
+	// maxCommandTail is the longest command tail that fits the PSP with its terminating carriage return.
+	maxCommandTail = 126
+	// maxCOMSize leaves room in the 64 KB segment for the PSP and the initial stack word.
+	maxCOMSize = 0x10000 - pspSize - 2
+)
+
+// LoadCOM loads a .COM image at ProgramSegment:0100 behind a Program Segment Prefix holding the command
+// tail, sets every segment register to ProgramSegment and SP to FFFE with a zero word on the stack, so a
+// final RET reaches the INT 20h at PSP:0000. Run stops when the program terminates.
+//
+// The command tail is stored as given; DOS usually starts it with a space.
+func (s *Simulator) LoadCOM(image []byte, commandTail string) error {
+	if len(image) > maxCOMSize {
+		return fmt.Errorf(".COM image is %d bytes, the limit is %d", len(image), maxCOMSize)
+	}
+	if err := s.buildPSP(ProgramSegment, memoryTopSegment, commandTail); err != nil {
+		return err
+	}
+	s.installHandlers()
+	s.Memory.Load(memory.Address(ProgramSegment, pspSize), image)
+
+	for _, reg := range []string{"cs", "ds", "es", "ss"} {
+		s.writeRegister(reg, ProgramSegment)
+	}
+	s.writeRegister("ip", pspSize)
+	s.writeRegister("sp", 0xFFFE)
+	s.Memory.Write16(memory.Address(ProgramSegment, 0xFFFE), 0)
+	s.runUntilTerminated()
+	return nil
+}
+
+// buildPSP writes a Program Segment Prefix at segment. end is the segment following the memory given to
+// the program.
+func (s *Simulator) buildPSP(segment, end uint16, commandTail string) error {
+	if len(commandTail) > maxCommandTail {
+		return fmt.Errorf("command tail is %d bytes, the limit is %d", len(commandTail), maxCommandTail)
+	}
+	psp := make([]byte, pspSize)
+	// INT 20h, so that jumping to PSP:0000 terminates the program
+	psp[0x00] = 0xCD
+	psp[0x01] = 0x20
+	psp[0x02] = byte(end)
+	psp[0x03] = byte(end >> 8)
+	psp[0x80] = byte(len(commandTail))
+	copy(psp[0x81:], commandTail)
+	psp[0x81+len(commandTail)] = '\r'
+	s.Memory.Load(memory.Address(segment, 0), psp)
+	return nil
+}
+
+// runUntilTerminated lifts the program bounds so Run only stops on HLT or program termination.
+func (s *Simulator) runUntilTerminated() {
+	s.programStart = 0
+	s.programEnd = memory.Size
+}
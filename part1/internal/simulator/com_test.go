@@ -0,0 +1,120 @@
+package simulator
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSimulatorLoadCOM(t *testing.T) {
+	tests := []struct {
+		name         string
+		program      []byte
+		commandTail  string
+		expectedExit byte
+		expected     map[string]uint16
+	}{
+		{
+			name: "int 21h function 4ch",
+			program: []byte{
+				0xb8, 0x03, 0x4c, // mov ax, 0x4c03
+				0xcd, 0x21, // int 33
+			},
+			expectedExit: 3,
+			expected:     map[string]uint16{"ds": ProgramSegment, "ss": ProgramSegment, "sp": 0xfff8},
+		},
+		{
+			name: "command tail length",
+			program: []byte{
+				0x8a, 0x06, 0x80, 0x00, // mov al, [0x80]
+				0xb4, 0x4c, // mov ah, 0x4c
+				0xcd, 0x21, // int 33
+			},
+			commandTail:  " a.txt",
+			expectedExit: 6,
+		},
+		{
+			name: "ret to int 20h in the psp",
+			program: []byte{
+				0xbb, 0x07, 0x00, // mov bx, 7
+				0xc3, // ret
+			},
+			expected: map[string]uint16{"bx": 7, "ip": 0x20, "sp": 0xfffa},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sim := NewSimulator(false)
+			if err := sim.LoadCOM(tt.program, tt.commandTail); err != nil {
+				t.Fatalf("Error loading program: %v", err)
+			}
+			if _, err := sim.Run(); err != nil {
+				t.Fatalf("Error running instructions: %v", err)
+			}
+			if !sim.Terminated() {
+				t.Fatalf("Expected the program to terminate")
+			}
+			if sim.ExitCode() != tt.expectedExit {
+				t.Fatalf("Expected exit code %d but got %d", tt.expectedExit, sim.ExitCode())
+			}
+			for reg, expected := range tt.expected {
+				if actual := sim.readRegister(reg); actual != expected {
+					t.Fatalf("Expected %s to be 0x%x but got 0x%x", reg, expected, actual)
+				}
+			}
+		})
+	}
+}
+
+func TestSimulatorLoadCOMLimits(t *testing.T) {
+	sim := NewSimulator(false)
+	if err := sim.LoadCOM(make([]byte, maxCOMSize+1), ""); err == nil {
+		t.Fatalf("Expected an error for an oversized image")
+	}
+	if err := sim.LoadCOM(nil, string(make([]byte, maxCommandTail+1))); err == nil {
+		t.Fatalf("Expected an error for an oversized command tail")
+	}
+}
+
+func TestSimulatorHandlerRunsOnceWithPendingIRQ(t *testing.T) {
+	sim := NewSimulator(false)
+	var stdout strings.Builder
+	sim.SetConsole(strings.NewReader(""), &stdout)
+	err := sim.LoadCOM([]byte{
+		0xb8, 0x08, 0x25, // mov ax, 0x2508
+		0xba, 0x18, 0x01, // mov dx, 0x118
+		0xcd, 0x21, // int 33
+		0xfb,       // sti
+		0xb4, 0x02, // mov ah, 2
+		0xb2, 0x78, // mov dl, 'x'
+		0x9c,                         // pushf
+		0x9a, 0x21, 0x00, 0x00, 0xf0, // call 0xf000:0x0021
+		0xb8, 0x00, 0x4c, // mov ax, 0x4c00
+		0xcd, 0x21, // int 33
+		0x83, 0xc7, 0x01, // add di, 1
+		0xb0, 0x20, // mov al, 0x20
+		0xe6, 0x20, // out 32, al (non-specific EOI)
+		0xcf, // iret
+	}, "")
+	if err != nil {
+		t.Fatalf("Error loading program: %v", err)
+	}
+
+	// run up to the chained call's handler, which is entered with IF set, then raise IRQ0 at the stub
+	for !sim.handlerDone || sim.readRegister("dl") != 'x' {
+		if _, err := sim.Step(); err != nil {
+			t.Fatalf("Error running instructions: %v", err)
+		}
+	}
+	sim.pic.raise(TimerIRQ)
+	if _, err := sim.Run(); err != nil {
+		t.Fatalf("Error running instructions: %v", err)
+	}
+
+	if stdout.String() != "x" {
+		t.Fatalf("Expected the handler to write %q once but got %q", "x", stdout.String())
+	}
+	if di := sim.readRegister("di"); di != 1 {
+		t.Fatalf("Expected the timer interrupt to be serviced once but got %d", di)
+	}
+}
@@ -0,0 +1,155 @@
+package simulator
+
+import (
+	"bufio"
+	"io"
+
+	"github.com/8086-simulator/part1/internal/memory"
+)
+
+const (
+	// dosVersionMajor and dosVersionMinor are reported by INT 21h function 30h.
+	dosVersionMajor = 5
+	dosVersionMinor = 0
+	// endOfFile is returned by the character input functions once stdin is exhausted.
+	endOfFile = 0x1A
+)
+
+// SetConsole sets the streams behind the DOS console functions. They default to the process's stdin
+// and stdout.
+func (s *Simulator) SetConsole(in io.Reader, out io.Writer) {
+	s.stdin = bufio.NewReader(in)
+	s.stdout = out
+}
+
+// int20 terminates the program with exit code 0.
+func (s *Simulator) int20() error {
+	s.terminate(0)
+	return nil
+}
+
+// int21 dispatches a DOS function call on AH.
+func (s *Simulator) int21() error {
+	function := byte(s.readRegister("ah"))
+	switch function {
+	case 0x01:
+		c := s.readConsole()
+		s.writeConsole(c)
+		s.writeRegister("al", uint16(c))
+	case 0x02:
+		s.writeConsole(byte(s.readRegister("dl")))
+		s.writeRegister("al", s.readRegister("dl"))
+	case 0x06:
+		s.directConsoleIO()
+	case 0x09:
+		s.writeString()
+	case 0x0A:
+		s.bufferedInput()
+	case 0x25:
+		vector := s.readRegister("al") * 4
+		s.Memory.Write16(memory.Address(0, vector), s.readRegister("dx"))
+		s.Memory.Write16(memory.Address(0, vector+2), s.readRegister("ds"))
+	case 0x30:
+		s.writeRegister("al", dosVersionMajor)
+		s.writeRegister("ah", dosVersionMinor)
+		s.writeRegister("bx", 0)
+		s.writeRegister("cx", 0)
+	case 0x35:
+		vector := s.readRegister("al") * 4
+		s.writeRegister("bx", s.Memory.Read16(memory.Address(0, vector)))
+		s.writeRegister("es", s.Memory.Read16(memory.Address(0, vector+2)))
+	case 0x4C:
+		s.terminate(byte(s.readRegister("al")))
+	default:
+		if !s.fileFunction(function) {
+			s.logger.Printf("unsupported INT 21h function 0x%02x", function)
+		}
+	}
+	return nil
+}
+
+// directConsoleIO implements function 06h: DL=FFh reads a character without waiting, reporting in ZF
+// whether one was available, and any other DL is written out.
+func (s *Simulator) directConsoleIO() {
+	if dl := byte(s.readRegister("dl")); dl != 0xFF {
+		s.writeConsole(dl)
+		s.writeRegister("al", uint16(dl))
+		return
+	}
+	c, err := s.stdin.ReadByte()
+	if err != nil {
+		s.writeRegister("al", 0)
+		s.setReturnFlag("Z", true)
+		return
+	}
+	s.writeRegister("al", uint16(c))
+	s.setReturnFlag("Z", false)
+}
+
+// writeString implements function 09h, writing the string at DS:DX up to the "$" terminator. A string
+// with no terminator in the 64 KB that follow DX is not written.
+func (s *Simulator) writeString() {
+	ds := s.readRegister("ds")
+	dx := s.readRegister("dx")
+	var text []byte
+	for i := range 0x10000 {
+		c := s.Memory.Read8(memory.Address(ds, dx+uint16(i)))
+		if c == '$' {
+			s.writeConsoleString(string(text))
+			s.writeRegister("al", '$')
+			return
+		}
+		text = append(text, c)
+	}
+	s.logger.Printf("INT 21h function 09h: no $ terminator after %04x:%04x", ds, dx)
+}
+
+// bufferedInput implements function 0Ah. The buffer at DS:DX holds its capacity in the first byte; the
+// line read, ended with a carriage return, goes from the third byte and its length into the second.
+func (s *Simulator) bufferedInput() {
+	ds := s.readRegister("ds")
+	buffer := s.readRegister("dx")
+	capacity := int(s.Memory.Read8(memory.Address(ds, buffer)))
+	if capacity == 0 {
+		return
+	}
+
+	var line []byte
+	for {
+		c, err := s.stdin.ReadByte()
+		if err != nil || c == '\n' {
+			break
+		}
+		// the carriage return is reserved, so the line holds at most capacity-1 characters
+		if c != '\r' && len(line) < capacity-1 {
+			line = append(line, c)
+		}
+	}
+	s.writeConsoleString(string(line) + "\r")
+
+	s.Memory.Write8(memory.Address(ds, buffer+1), byte(len(line)))
+	line = append(line, '\r')
+	s.Memory.Load(memory.Address(ds, buffer+2), line)
+}
+
+// readConsole reads one character from stdin, returning Ctrl-Z once it is exhausted.
+func (s *Simulator) readConsole() byte {
+	c, err := s.stdin.ReadByte()
+	if err != nil {
+		if err != io.EOF {
+			s.logger.Printf("reading console: %v", err)
+		}
+		return endOfFile
+	}
+	return c
+}
+
+func (s *Simulator) writeConsole(c byte) {
+	s.writeConsoleString(string([]byte{c}))
+}
+
+func (s *Simulator) writeConsoleString(text string) {
+	if _, err := io.WriteString(s.stdout, text); err != nil {
+		s.logger.Printf("writing console: %v", err)
+	}
+}
@@ -0,0 +1,173 @@
+package simulator
+
+import (
+	"log"
+	"strings"
+	"testing"
+
+	"github.com/8086-simulator/part1/internal/memory"
+)
+
+func TestSimulatorDOSConsole(t *testing.T) {
+	tests := []struct {
+		name           string
+		program        []byte
+		stdin          string
+		expectedOutput string
+		expectedExit   byte
+		expected       map[string]uint16
+		expectedMemory map[uint16]byte
+	}{
+		{
+			name: "write string",
+			program: []byte{
+				0xba, 0x0c, 0x01, // mov dx, 0x10c
+				0xb4, 0x09, // mov ah, 9
+				0xcd, 0x21, // int 33
+				0xb8, 0x00, 0x4c, // mov ax, 0x4c00
+				0xcd, 0x21, // int 33
+				'H', 'i', '!', '$',
+			},
+			expectedOutput: "Hi!",
+		},
+		{
+			name: "read and write a character",
+			program: []byte{
+				0xb4, 0x01, // mov ah, 1
+				0xcd, 0x21, // int 33
+				0x88, 0xc2, // mov dl, al
+				0xb4, 0x02, // mov ah, 2
+				0xcd, 0x21, // int 33
+				0xb4, 0x4c, // mov ah, 0x4c
+				0xcd, 0x21, // int 33
+			},
+			stdin: "x",
+			// function 01h echoes the character before function 02h writes it again
+			expectedOutput: "xx",
+			expectedExit:   'x',
+		},
+		{
+			name: "buffered input",
+			program: []byte{
+				0xba, 0x00, 0x02, // mov dx, 0x200
+				0xc6, 0x06, 0x00, 0x02, 0x04, // mov byte [0x200], 4
+				0xb4, 0x0a, // mov ah, 10
+				0xcd, 0x21, // int 33
+				0x8a, 0x06, 0x01, 0x02, // mov al, [0x201]
+				0xb4, 0x4c, // mov ah, 0x4c
+				0xcd, 0x21, // int 33
+			},
+			stdin:          "hello\r\nworld",
+			expectedOutput: "hel\r",
+			expectedExit:   3,
+			expectedMemory: map[uint16]byte{0x202: 'h', 0x203: 'e', 0x204: 'l', 0x205: '\r'},
+		},
+		{
+			name: "read a line from stdin",
+			program: []byte{
+				0xb4, 0x3f, // mov ah, 0x3f
+				0xbb, 0x00, 0x00, // mov bx, 0
+				0xb9, 0x80, 0x00, // mov cx, 128
+				0xba, 0x00, 0x02, // mov dx, 0x200
+				0xcd, 0x21, // int 33
+				0xb4, 0x4c, // mov ah, 0x4c
+				0xcd, 0x21, // int 33
+			},
+			stdin: "ab\r\ncd",
+			// the read stops after the line feed, so AL holds the count
+			expectedExit:   4,
+			expectedMemory: map[uint16]byte{0x200: 'a', 0x201: 'b', 0x202: '\r', 0x203: '\n', 0x204: 0},
+		},
+		{
+			name: "direct console input without a character",
+			program: []byte{
+				0xb2, 0xff, // mov dl, 0xff
+				0xb4, 0x06, // mov ah, 6
+				0xcd, 0x21, // int 33
+				0x9c,       // pushf
+				0x5b,       // pop bx
+				0xb4, 0x4c, // mov ah, 0x4c
+				0xcd, 0x21, // int 33
+			},
+			// ZF comes back set in the flags the handler returns
+			expected: map[string]uint16{"bx": 0xf042},
+		},
+		{
+			name: "version and interrupt vectors",
+			program: []byte{
+				0xb4, 0x30, // mov ah, 0x30
+				0xcd, 0x21, // int 33
+				0x89, 0xc6, // mov si, ax
+				0xb8, 0x60, 0x25, // mov ax, 0x2560
+				0xba, 0x34, 0x12, // mov dx, 0x1234
+				0xcd, 0x21, // int 33
+				0xb8, 0x60, 0x35, // mov ax, 0x3560
+				0xcd, 0x21, // int 33
+				0xb8, 0x00, 0x4c, // mov ax, 0x4c00
+				0xcd, 0x21, // int 33
+			},
+			expected: map[string]uint16{"si": dosVersionMajor, "bx": 0x1234, "es": ProgramSegment},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sim := NewSimulator(false)
+			var stdout strings.Builder
+			sim.SetConsole(strings.NewReader(tt.stdin), &stdout)
+			if err := sim.LoadCOM(tt.program, ""); err != nil {
+				t.Fatalf("Error loading program: %v", err)
+			}
+			if _, err := sim.Run(); err != nil {
+				t.Fatalf("Error running instructions: %v", err)
+			}
+
+			if !sim.Terminated() || sim.ExitCode() != tt.expectedExit {
+				t.Fatalf("Expected exit code %d but got %d (terminated %t)", tt.expectedExit, sim.ExitCode(), sim.Terminated())
+			}
+			if stdout.String() != tt.expectedOutput {
+				t.Fatalf("Expected output %q but got %q", tt.expectedOutput, stdout.String())
+			}
+			for register, expected := range tt.expected {
+				if got := sim.readRegister(register); got != expected {
+					t.Fatalf("Expected %s to be 0x%x but got 0x%x", register, expected, got)
+				}
+			}
+			for offset, expected := range tt.expectedMemory {
+				if got := sim.Memory.Read8(memory.Address(ProgramSegment, offset)); got != expected {
+					t.Fatalf("Expected byte at 0x%x to be 0x%x but got 0x%x", offset, expected, got)
+				}
+			}
+		})
+	}
+}
+
+func TestSimulatorDOSWriteStringWithoutTerminator(t *testing.T) {
+	sim := NewSimulator(false)
+	var stdout, logs strings.Builder
+	sim.SetConsole(strings.NewReader(""), &stdout)
+	sim.SetLogger(log.New(&logs, "", 0))
+	err := sim.LoadCOM([]byte{
+		0xba, 0x00, 0x02, // mov dx, 0x200
+		0xb4, 0x09, // mov ah, 9
+		0xcd, 0x21, // int 33
+		0xb8, 0x00, 0x4c, // mov ax, 0x4c00
+		0xcd, 0x21, // int 33
+	}, "")
+	if err != nil {
+		t.Fatalf("Error loading program: %v", err)
+	}
+	if _, err := sim.Run(); err != nil {
+		t.Fatalf("Error running instructions: %v", err)
+	}
+
+	if !sim.Terminated() {
+		t.Fatalf("Expected the program to terminate")
+	}
+	if stdout.String() != "" {
+		t.Fatalf("Expected no output but got %q", stdout.String())
+	}
+	if !strings.Contains(logs.String(), "no $ terminator") {
+		t.Fatalf("Expected the missing terminator to be logged but got %q", logs.String())
+	}
+}
@@ -0,0 +1,97 @@
+package simulator
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/8086-simulator/part1/internal/memory"
+)
+
+const (
+	mzSignature  = "MZ"
+	mzHeaderSize = 0x1C
+	mzPageSize   = 512
+	// paragraphSize is the granularity of DOS memory allocation.
+	paragraphSize = 16
+)
+
+// mzHeader is the fixed part of a DOS MZ executable header.
+type mzHeader struct {
+	Signature        [2]byte
+	LastPageBytes    uint16
+	Pages            uint16
+	Relocations      uint16
+	HeaderParagraphs uint16
+	MinAlloc         uint16
+	MaxAlloc         uint16
+	SS               uint16
+	SP               uint16
+	Checksum         uint16
+	IP               uint16
+	CS               uint16
+	RelocationTable  uint16
+	Overlay          uint16
+}
+
+// imageSize returns the size of the load module that follows the header.
+func (h *mzHeader) imageSize() int {
+	size := int(h.Pages) * mzPageSize
+	if h.LastPageBytes != 0 {
+		size -= mzPageSize - int(h.LastPageBytes)
+	}
+	return size - int(h.HeaderParagraphs)*paragraphSize
+}
+
+// LoadEXE loads an MZ executable behind a Program Segment Prefix at ProgramSegment. The load module goes
+// right after the PSP, the relocation table is applied relative to it and CS:IP and SS:SP come from the
+// header. The program gets its minimum allocation past the image, and up to its maximum if memory
+// allows. DS and ES point at the PSP. Run stops when the program terminates.
+func (s *Simulator) LoadEXE(file []byte, commandTail string) error {
+	if len(file) < mzHeaderSize || string(file[:2]) != mzSignature {
+		return fmt.Errorf("not an MZ executable")
+	}
+	var h mzHeader
+	if _, err := binary.Decode(file, binary.LittleEndian, &h); err != nil {
+		return fmt.Errorf("reading MZ header: %w", err)
+	}
+	start := int(h.HeaderParagraphs) * paragraphSize
+	size := h.imageSize()
+	if size < 0 || start+size > len(file) {
+		return fmt.Errorf("MZ header describes %d image bytes at %d but the file has %d bytes", size, start, len(file))
+	}
+	relocationsEnd := int(h.RelocationTable) + int(h.Relocations)*4
+	if relocationsEnd > len(file) {
+		return fmt.Errorf("relocation table ends at %d but the file has %d bytes", relocationsEnd, len(file))
+	}
+
+	loadSegment := ProgramSegment + pspSize/paragraphSize
+	imageParagraphs := (size + paragraphSize - 1) / paragraphSize
+	available := int(memoryTopSegment - ProgramSegment)
+	needed := pspSize/paragraphSize + imageParagraphs + int(h.MinAlloc)
+	if needed > available {
+		return fmt.Errorf("program needs %d paragraphs but only %d are available", needed, available)
+	}
+	allocated := min(available, pspSize/paragraphSize+imageParagraphs+int(h.MaxAlloc))
+	if err := s.buildPSP(ProgramSegment, ProgramSegment+uint16(allocated), commandTail); err != nil {
+		return err
+	}
+	s.installHandlers()
+	s.Memory.Load(memory.Address(loadSegment, 0), file[start:start+size])
+
+	for i := range int(h.Relocations) {
+		entry := file[int(h.RelocationTable)+i*4:]
+		offset := binary.LittleEndian.Uint16(entry)
+		segment := binary.LittleEndian.Uint16(entry[2:])
+		addr := memory.Address(loadSegment+segment, offset)
+		s.Memory.Write16(addr, s.Memory.Read16(addr)+loadSegment)
+	}
+
+	s.writeRegister("cs", loadSegment+h.CS)
+	s.writeRegister("ip", h.IP)
+	s.writeRegister("ss", loadSegment+h.SS)
+	s.writeRegister("sp", h.SP)
+	s.writeRegister("ds", ProgramSegment)
+	s.writeRegister("es", ProgramSegment)
+	s.runUntilTerminated()
+	return nil
+}
@@ -0,0 +1,58 @@
+package simulator
+
+import "testing"
+
+func TestSimulatorLoadEXE(t *testing.T) {
+	code := []byte{
+		0xb8, 0x01, 0x00, // mov ax, 1 (relocated to the segment of the data below)
+		0x8e, 0xd8, // mov ds, ax
+		0x8a, 0x06, 0x00, 0x00, // mov al, [0]
+		0xb4, 0x4c, // mov ah, 0x4c
+		0xcd, 0x21, // int 33
+		0x90, 0x90, 0x90, // padding to the next paragraph
+		0x2a, // data: 42
+	}
+	sim := NewSimulator(false)
+	if err := sim.LoadEXE(mzFile(code, 0x10, 0xffff, 1), " x"); err != nil {
+		t.Fatalf("Error loading program: %v", err)
+	}
+
+	loadSegment := ProgramSegment + 0x10
+	expected := map[string]uint16{"cs": loadSegment, "ip": 0, "ss": loadSegment + 0x10, "sp": 0x100, "ds": ProgramSegment, "es": ProgramSegment}
+	for reg, value := range expected {
+		if actual := sim.readRegister(reg); actual != value {
+			t.Fatalf("Expected %s to be 0x%x but got 0x%x", reg, value, actual)
+		}
+	}
+	if top := sim.Memory.Read16(0x10002); top != memoryTopSegment {
+		t.Fatalf("Expected the PSP to end memory at 0x%x but got 0x%x", memoryTopSegment, top)
+	}
+
+	if _, err := sim.Run(); err != nil {
+		t.Fatalf("Error running instructions: %v", err)
+	}
+	if !sim.Terminated() || sim.ExitCode() != 42 {
+		t.Fatalf("Expected the program to exit with 42 but got %d (terminated %t)", sim.ExitCode(), sim.Terminated())
+	}
+	if ds := sim.readRegister("ds"); ds != loadSegment+1 {
+		t.Fatalf("Expected ds to be 0x%x but got 0x%x", loadSegment+1, ds)
+	}
+}
+
+func TestSimulatorLoadEXEAllocation(t *testing.T) {
+	sim := NewSimulator(false)
+	if err := sim.LoadEXE(mzFile([]byte{0xf4}, 0, 0x20), ""); err != nil {
+		t.Fatalf("Error loading program: %v", err)
+	}
+	// psp, one image paragraph and the maximum allocation
+	if top := sim.Memory.Read16(0x10002); top != ProgramSegment+0x10+1+0x20 {
+		t.Fatalf("Expected the PSP to end memory at 0x%x but got 0x%x", ProgramSegment+0x31, top)
+	}
+
+	if err := sim.LoadEXE(mzFile([]byte{0xf4}, 0xffff, 0xffff), ""); err == nil {
+		t.Fatalf("Expected an error when the minimum allocation does not fit")
+	}
+	if err := sim.LoadEXE([]byte("not an executable, long enough"), ""); err == nil {
+		t.Fatalf("Expected an error for a file without an MZ header")
+	}
+}
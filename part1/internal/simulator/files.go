@@ -0,0 +1,331 @@
+package simulator
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/8086-simulator/part1/internal/memory"
+)
+
+// DOS error codes returned in AX with CF set.
+const (
+	dosErrInvalidFunction = 0x01
+	dosErrFileNotFound    = 0x02
+	dosErrPathNotFound    = 0x03
+	dosErrTooManyFiles    = 0x04
+	dosErrAccessDenied    = 0x05
+	dosErrInvalidHandle   = 0x06
+	dosErrInvalidAccess   = 0x0C
+)
+
+const (
+	stdinHandle  = 0
+	stdoutHandle = 1
+	stderrHandle = 2
+	// firstFileHandle follows the five handles DOS opens for every program.
+	firstFileHandle = 5
+	maxOpenFiles    = 20
+	// maxPathLength bounds the ASCIIZ path read from guest memory.
+	maxPathLength = 128
+)
+
+// MountDrive makes a host directory the guest's drive C: for the DOS file functions, replacing any
+// drive mounted before. Paths cannot leave the directory, and names are matched case-insensitively
+// like on DOS.
+func (s *Simulator) MountDrive(dir string) error {
+	root, err := os.OpenRoot(dir)
+	if err != nil {
+		return err
+	}
+	s.unmountDrive()
+	s.drive = root
+	return nil
+}
+
+// unmountDrive closes the mounted drive and every file handle open on it.
+func (s *Simulator) unmountDrive() {
+	for _, f := range s.files {
+		f.Close()
+	}
+	s.files = make(map[uint16]*os.File)
+	if s.drive != nil {
+		s.drive.Close()
+		s.drive = nil
+	}
+}
+
+// fileFunction runs a DOS file handle function, reporting failures in CF and AX like DOS does. It
+// returns false when AH is not a file function.
+func (s *Simulator) fileFunction(function byte) bool {
+	var result, code uint16
+	switch function {
+	case 0x3C:
+		result, code = s.openFile(os.O_RDWR|os.O_CREATE|os.O_TRUNC, true)
+	case 0x3D:
+		flags := map[byte]int{0: os.O_RDONLY, 1: os.O_WRONLY, 2: os.O_RDWR}
+		mode, ok := flags[byte(s.readRegister("al"))&0x07]
+		if !ok {
+			code = dosErrInvalidAccess
+			break
+		}
+		result, code = s.openFile(mode, false)
+	case 0x3E:
+		result, code = s.closeFile()
+	case 0x3F:
+		result, code = s.readFile()
+	case 0x40:
+		result, code = s.writeFile()
+	case 0x41:
+		code = s.deleteFile()
+	case 0x42:
+		result, code = s.seekFile()
+	default:
+		return false
+	}
+
+	if code != 0 {
+		s.writeRegister("ax", code)
+		s.setReturnFlag("C", true)
+		return true
+	}
+	s.writeRegister("ax", result)
+	s.setReturnFlag("C", false)
+	return true
+}
+
+// openFile opens the file named at DS:DX and returns its new handle.
+func (s *Simulator) openFile(flags int, create bool) (uint16, uint16) {
+	name, code := s.hostPath(create)
+	if code != 0 {
+		return 0, code
+	}
+	handle, ok := s.freeHandle()
+	if !ok {
+		return 0, dosErrTooManyFiles
+	}
+	f, err := s.drive.OpenFile(name, flags, 0o644)
+	if err != nil {
+		return 0, dosError(err)
+	}
+	s.files[handle] = f
+	return handle, 0
+}
+
+func (s *Simulator) closeFile() (uint16, uint16) {
+	handle := s.readRegister("bx")
+	f, ok := s.files[handle]
+	if !ok {
+		if handle < firstFileHandle {
+			return 0, 0
+		}
+		return 0, dosErrInvalidHandle
+	}
+	delete(s.files, handle)
+	if err := f.Close(); err != nil {
+		return 0, dosErrAccessDenied
+	}
+	return 0, 0
+}
+
+// readFile reads CX bytes from handle BX into DS:DX and returns the count read; 0 means end of file.
+// Reading stdin returns once a line is complete, like DOS reading the console.
+func (s *Simulator) readFile() (uint16, uint16) {
+	handle := s.readRegister("bx")
+	buffer := make([]byte, s.readRegister("cx"))
+	var n int
+	var err error
+	switch f, ok := s.files[handle]; {
+	case ok:
+		n, err = io.ReadFull(f, buffer)
+	case handle == stdinHandle:
+		n, err = s.readLine(buffer)
+	default:
+		return 0, dosErrInvalidHandle
+	}
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return 0, dosErrAccessDenied
+	}
+	s.Memory.Load(memory.Address(s.readRegister("ds"), s.readRegister("dx")), buffer[:n])
+	return uint16(n), 0
+}
+
+// readLine reads stdin into buffer up to and including the first line feed, stopping early when the
+// buffer is full.
+func (s *Simulator) readLine(buffer []byte) (int, error) {
+	for n := range buffer {
+		c, err := s.stdin.ReadByte()
+		if err != nil {
+			return n, err
+		}
+		buffer[n] = c
+		if c == '\n' {
+			return n + 1, nil
+		}
+	}
+	return len(buffer), nil
+}
+
+// writeFile writes CX bytes from DS:DX to handle BX and returns the count written. Writing zero bytes
+// truncates the file at the current position.
+func (s *Simulator) writeFile() (uint16, uint16) {
+	handle := s.readRegister("bx")
+	count := s.readRegister("cx")
+	ds := s.readRegister("ds")
+	dx := s.readRegister("dx")
+	buffer := make([]byte, count)
+	for i := range buffer {
+		buffer[i] = s.Memory.Read8(memory.Address(ds, dx+uint16(i)))
+	}
+
+	f, ok := s.files[handle]
+	switch {
+	case !ok && (handle == stdoutHandle || handle == stderrHandle):
+		s.writeConsoleString(string(buffer))
+		return count, 0
+	case !ok:
+		return 0, dosErrInvalidHandle
+	case count == 0:
+		position, err := f.Seek(0, io.SeekCurrent)
+		if err != nil || f.Truncate(position) != nil {
+			return 0, dosErrAccessDenied
+		}
+		return 0, 0
+	}
+	n, err := f.Write(buffer)
+	if err != nil {
+		return uint16(n), dosErrAccessDenied
+	}
+	return uint16(n), 0
+}
+
+func (s *Simulator) deleteFile() uint16 {
+	name, code := s.hostPath(false)
+	if code != 0 {
+		return code
+	}
+	if err := s.drive.Remove(name); err != nil {
+		return dosError(err)
+	}
+	return 0
+}
+
+// seekFile moves handle BX by the signed offset CX:DX from the origin in AL and returns the new
+// position in DX:AX.
+func (s *Simulator) seekFile() (uint16, uint16) {
+	f, ok := s.files[s.readRegister("bx")]
+	if !ok {
+		return 0, dosErrInvalidHandle
+	}
+	whence := int(s.readRegister("al"))
+	if whence > io.SeekEnd {
+		return 0, dosErrInvalidFunction
+	}
+	offset := int64(int32(uint32(s.readRegister("cx"))<<16 | uint32(s.readRegister("dx"))))
+	position, err := f.Seek(offset, whence)
+	if err != nil {
+		return 0, dosErrAccessDenied
+	}
+	s.writeRegister("dx", uint16(position>>16))
+	return uint16(position), 0
+}
+
+// freeHandle returns the lowest handle not in use.
+func (s *Simulator) freeHandle() (uint16, bool) {
+	for handle := uint16(firstFileHandle); handle < maxOpenFiles; handle++ {
+		if _, ok := s.files[handle]; !ok {
+			return handle, true
+		}
+	}
+	return 0, false
+}
+
+// hostPath translates the ASCIIZ DOS path at DS:DX into a path inside the mounted drive. Each existing
+// component is matched case-insensitively; when create is set the last one may not exist yet.
+func (s *Simulator) hostPath(create bool) (string, uint16) {
+	if s.drive == nil {
+		return "", dosErrPathNotFound
+	}
+	name := s.readASCIIZ(s.readRegister("ds"), s.readRegister("dx"))
+	if len(name) >= 2 && name[1] == ':' {
+		if !strings.EqualFold(name[:1], "c") {
+			return "", dosErrPathNotFound
+		}
+		name = name[2:]
+	}
+
+	var resolved []string
+	components := strings.FieldsFunc(name, func(r rune) bool { return r == '\\' || r == '/' })
+	for i, component := range components {
+		dir := path.Join(append([]string{"."}, resolved...)...)
+		// the root already keeps paths inside the drive; this reports access denied rather than a missing file
+		if dir == ".." || strings.HasPrefix(dir, "../") {
+			return "", dosErrAccessDenied
+		}
+		match, ok := s.matchName(dir, component)
+		if !ok && (!create || i < len(components)-1) {
+			if i < len(components)-1 {
+				return "", dosErrPathNotFound
+			}
+			return "", dosErrFileNotFound
+		}
+		if !ok {
+			match = component
+		}
+		resolved = append(resolved, match)
+	}
+	hostName := path.Join(resolved...)
+	if len(resolved) == 0 || hostName == "." {
+		return "", dosErrPathNotFound
+	}
+	if hostName == ".." || strings.HasPrefix(hostName, "../") {
+		return "", dosErrAccessDenied
+	}
+	return hostName, 0
+}
+
+// matchName finds the entry of dir whose name equals name ignoring case, preferring an exact match.
+func (s *Simulator) matchName(dir, name string) (string, bool) {
+	if name == "." || name == ".." {
+		return name, true
+	}
+	entries, err := fs.ReadDir(s.drive.FS(), dir)
+	if err != nil {
+		return "", false
+	}
+	match, found := "", false
+	for _, entry := range entries {
+		if entry.Name() == name {
+			return name, true
+		}
+		if !found && strings.EqualFold(entry.Name(), name) {
+			match, found = entry.Name(), true
+		}
+	}
+	return match, found
+}
+
+func (s *Simulator) readASCIIZ(segment, offset uint16) string {
+	var name []byte
+	for i := range uint16(maxPathLength) {
+		c := s.Memory.Read8(memory.Address(segment, offset+i))
+		if c == 0 {
+			break
+		}
+		name = append(name, c)
+	}
+	return string(name)
+}
+
+// dosError maps a host error to the closest DOS error code.
+func dosError(err error) uint16 {
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		return dosErrFileNotFound
+	default:
+		return dosErrAccessDenied
+	}
+}
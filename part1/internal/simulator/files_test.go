@@ -0,0 +1,214 @@
+package simulator
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/8086-simulator/part1/internal/memory"
+)
+
+// filesProgram pads code to 0x80 bytes and appends ASCIIZ names, so the first name is at 0x180, the
+// second at 0x190 and so on.
+func filesProgram(code []byte, names ...string) []byte {
+	program := make([]byte, 0x80)
+	copy(program, code)
+	for _, name := range names {
+		entry := make([]byte, 0x10)
+		copy(entry, name)
+		program = append(program, entry...)
+	}
+	return program
+}
+
+func TestSimulatorDOSFiles(t *testing.T) {
+	openData := []byte{
+		0xba, 0x80, 0x01, // mov dx, 0x180
+		0xb8, 0x00, 0x3d, // mov ax, 0x3d00
+		0xcd, 0x21, // int 33
+	}
+	exit := []byte{
+		0xb4, 0x4c, // mov ah, 0x4c
+		0xcd, 0x21, // int 33
+	}
+
+	tests := []struct {
+		name           string
+		code           []byte
+		names          []string
+		expectedExit   byte
+		expectedFiles  map[string]string
+		expectedMemory string
+	}{
+		{
+			name: "copy a file",
+			code: []byte{
+				0xba, 0x80, 0x01, // mov dx, 0x180
+				0xb8, 0x00, 0x3d, // mov ax, 0x3d00
+				0xcd, 0x21, // int 33
+				0x89, 0xc3, // mov bx, ax
+				0xb4, 0x3f, // mov ah, 0x3f
+				0xb9, 0x10, 0x00, // mov cx, 16
+				0xba, 0x00, 0x03, // mov dx, 0x300
+				0xcd, 0x21, // int 33
+				0x89, 0xc6, // mov si, ax
+				0xb4, 0x3e, // mov ah, 0x3e
+				0xcd, 0x21, // int 33
+				0xba, 0x90, 0x01, // mov dx, 0x190
+				0xb4, 0x3c, // mov ah, 0x3c
+				0xb9, 0x00, 0x00, // mov cx, 0
+				0xcd, 0x21, // int 33
+				0x89, 0xc3, // mov bx, ax
+				0xb4, 0x40, // mov ah, 0x40
+				0x89, 0xf1, // mov cx, si
+				0xba, 0x00, 0x03, // mov dx, 0x300
+				0xcd, 0x21, // int 33
+				0xb4, 0x3e, // mov ah, 0x3e
+				0xcd, 0x21, // int 33
+				0xb8, 0x00, 0x4c, // mov ax, 0x4c00
+				0xcd, 0x21, // int 33
+			},
+			names:          []string{"DATA.TXT", `C:\OUT.TXT`},
+			expectedFiles:  map[string]string{"data.txt": "hello", "OUT.TXT": "hello"},
+			expectedMemory: "hello",
+		},
+		{
+			name:         "open returns the first free handle",
+			code:         append(openData, exit...),
+			names:        []string{"data.txt"},
+			expectedExit: firstFileHandle,
+		},
+		{
+			name:         "open a missing file",
+			code:         append(openData, exit...),
+			names:        []string{"MISSING.TXT"},
+			expectedExit: dosErrFileNotFound,
+		},
+		{
+			name:         "open outside the drive",
+			code:         append(openData, exit...),
+			names:        []string{`..\SECRET.TXT`},
+			expectedExit: dosErrAccessDenied,
+		},
+		{
+			name:         "open on another drive",
+			code:         append(openData, exit...),
+			names:        []string{`A:\DATA.TXT`},
+			expectedExit: dosErrPathNotFound,
+		},
+		{
+			name: "seek from the end and read",
+			code: append(openData,
+				0x89, 0xc3, // mov bx, ax
+				0xb8, 0x02, 0x42, // mov ax, 0x4202
+				0xb9, 0xff, 0xff, // mov cx, 0xffff
+				0xba, 0xfe, 0xff, // mov dx, 0xfffe
+				0xcd, 0x21, // int 33
+				0x89, 0xc6, // mov si, ax
+				0xb4, 0x3f, // mov ah, 0x3f
+				0xb9, 0x02, 0x00, // mov cx, 2
+				0xba, 0x00, 0x03, // mov dx, 0x300
+				0xcd, 0x21, // int 33
+				0x89, 0xf0, // mov ax, si
+				0xb4, 0x4c, // mov ah, 0x4c
+				0xcd, 0x21, // int 33
+			),
+			names:          []string{"DATA.TXT"},
+			expectedExit:   3,
+			expectedMemory: "lo",
+		},
+		{
+			name: "delete a file",
+			code: []byte{
+				0xba, 0x80, 0x01, // mov dx, 0x180
+				0xb4, 0x41, // mov ah, 0x41
+				0xcd, 0x21, // int 33
+				0xb8, 0x00, 0x4c, // mov ax, 0x4c00
+				0xcd, 0x21, // int 33
+			},
+			names:         []string{"DATA.TXT"},
+			expectedFiles: map[string]string{"data.txt": ""},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			base := t.TempDir()
+			drive := filepath.Join(base, "c")
+			if err := os.Mkdir(drive, 0o755); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(filepath.Join(drive, "data.txt"), []byte("hello"), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(filepath.Join(base, "secret.txt"), []byte("secret"), 0o644); err != nil {
+				t.Fatal(err)
+			}
+
+			sim := NewSimulator(false)
+			if err := sim.MountDrive(drive); err != nil {
+				t.Fatalf("Error mounting drive: %v", err)
+			}
+			if err := sim.LoadCOM(filesProgram(tt.code, tt.names...), ""); err != nil {
+				t.Fatalf("Error loading program: %v", err)
+			}
+			if _, err := sim.Run(); err != nil {
+				t.Fatalf("Error running instructions: %v", err)
+			}
+
+			if !sim.Terminated() || sim.ExitCode() != tt.expectedExit {
+				t.Fatalf("Expected exit code %d but got %d (terminated %t)", tt.expectedExit, sim.ExitCode(), sim.Terminated())
+			}
+			// an empty expected content means the file must not exist
+			for name, expected := range tt.expectedFiles {
+				content, err := os.ReadFile(filepath.Join(drive, name))
+				if expected == "" {
+					if !os.IsNotExist(err) {
+						t.Fatalf("Expected %s to be deleted", name)
+					}
+					continue
+				}
+				if err != nil || string(content) != expected {
+					t.Fatalf("Expected %s to contain %q but got %q (%v)", name, expected, content, err)
+				}
+			}
+			for i, expected := range []byte(tt.expectedMemory) {
+				if got := sim.Memory.Read8(memory.Address(ProgramSegment, 0x300+uint16(i))); got != expected {
+					t.Fatalf("Expected byte %d of the buffer to be %q but got %q", i, expected, got)
+				}
+			}
+		})
+	}
+}
+
+func TestSimulatorMountDriveReplacesDrive(t *testing.T) {
+	first := t.TempDir()
+	second := t.TempDir()
+	if err := os.WriteFile(filepath.Join(second, "data.txt"), []byte("hello"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	sim := NewSimulator(false)
+	if err := sim.MountDrive(first); err != nil {
+		t.Fatalf("Error mounting drive: %v", err)
+	}
+	if err := sim.MountDrive(second); err != nil {
+		t.Fatalf("Error mounting drive: %v", err)
+	}
+	if sim.drive.Name() != second {
+		t.Fatalf("Expected drive %s but got %s", second, sim.drive.Name())
+	}
+
+	f, err := sim.drive.Open("data.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sim.files[firstFileHandle] = f
+	sim.Init()
+	if sim.drive != nil || len(sim.files) != 0 {
+		t.Fatalf("Expected Init to unmount the drive and close %d open files", len(sim.files))
+	}
+	if _, err := f.Stat(); err == nil {
+		t.Fatalf("Expected the open file to be closed")
+	}
+}
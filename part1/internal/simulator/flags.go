@@ -0,0 +1,59 @@
+package simulator
+
+import "math/bits"
+
+// setArithmeticFlags sets CF, PF, AF, ZF, SF and OF for an ADD or SUB of source into dest.
+func (s *Simulator) setArithmeticFlags(subtract bool, dest, source, result uint32, wide bool) {
+	signBit := uint32(0x80)
+	mask := uint32(0xFF)
+	if wide {
+		signBit = 0x8000
+		mask = 0xFFFF
+	}
+
+	s.flags["C"] = result&^mask != 0
+	s.flags["A"] = (dest^source^result)&0x10 != 0
+	if subtract {
+		s.flags["O"] = (dest^source)&(dest^result)&signBit != 0
+	} else {
+		s.flags["O"] = ^(dest^source)&(dest^result)&signBit != 0
+	}
+	s.setResultFlags(uint16(result&mask), wide)
+}
+
+// setResultFlags sets PF, ZF and SF from a result value.
+func (s *Simulator) setResultFlags(result uint16, wide bool) {
+	s.flags["P"] = bits.OnesCount8(uint8(result))%2 == 0
+	s.flags["Z"] = truncate(result, wide) == 0
+	s.flags["S"] = isNegative(result, wide)
+}
+
+// flagBits maps each flag to its bit in the FLAGS register.
+var flagBits = map[string]uint16{
+	"C": 1 << 0,
+	"P": 1 << 2,
+	"A": 1 << 4,
+	"Z": 1 << 6,
+	"S": 1 << 7,
+	"T": 1 << 8,
+	"I": 1 << 9,
+	"D": 1 << 10,
+	"O": 1 << 11,
+}
+
+// flagsWord packs the flags into the FLAGS register layout. Bit 1 and bits 12-15 always read as set on the 8086.
+func (s *Simulator) flagsWord() uint16 {
+	word := uint16(0xF002)
+	for flag, bit := range flagBits {
+		if s.flags[flag] {
+			word |= bit
+		}
+	}
+	return word
+}
+
+func (s *Simulator) setFlagsWord(word uint16) {
+	for flag, bit := range flagBits {
+		s.flags[flag] = word&bit != 0
+	}
+}
@@ -0,0 +1,168 @@
+package simulator
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/8086-simulator/part1/internal/memory"
+)
+
+const (
+	sectorSize = 512
+	// bootSegment:bootOffset is where the BIOS loads the boot sector.
+	bootSegment uint16 = 0x0000
+	bootOffset  uint16 = 0x7C00
+)
+
+// INT 13h status codes returned in AH.
+const (
+	diskOK             = 0x00
+	diskBadCommand     = 0x01
+	diskSectorNotFound = 0x04
+	diskWriteFault     = 0x0A
+	diskTimeout        = 0x80
+)
+
+// Disk is the storage behind an emulated floppy drive, usually an *os.File opened on an image.
+type Disk interface {
+	io.ReaderAt
+	io.WriterAt
+}
+
+// floppyGeometry describes a standard PC floppy format. driveType is what INT 13h function 08h
+// reports in BL.
+type floppyGeometry struct {
+	cylinders, heads, sectors int
+	driveType                 byte
+}
+
+// floppyFormats maps image sizes to their geometry.
+var floppyFormats = map[int64]floppyGeometry{
+	368640:  {40, 2, 9, 1},
+	737280:  {80, 2, 9, 3},
+	1228800: {80, 2, 15, 2},
+	1474560: {80, 2, 18, 4},
+}
+
+// floppy is drive A:.
+type floppy struct {
+	disk Disk
+	floppyGeometry
+	// status is the result of the last operation, returned by function 01h.
+	status byte
+}
+
+// BootFloppy inserts a floppy image of the given size as drive A: and boots from it: sector 0 is
+// loaded at 0000:7C00 and executed with DL holding the boot drive, and INT 13h serves sector reads
+// and writes against the image. Run stops on HLT.
+func (s *Simulator) BootFloppy(disk Disk, size int64) error {
+	geometry, ok := floppyFormats[size]
+	if !ok {
+		return fmt.Errorf("unsupported floppy image size %d", size)
+	}
+	boot := make([]byte, sectorSize)
+	if _, err := disk.ReadAt(boot, 0); err != nil {
+		return fmt.Errorf("reading boot sector: %w", err)
+	}
+
+	s.installHandlers()
+	s.floppy = &floppy{disk: disk, floppyGeometry: geometry}
+	s.Memory.Load(memory.Address(bootSegment, bootOffset), boot)
+	for _, reg := range []string{"cs", "ds", "es", "ss"} {
+		s.writeRegister(reg, bootSegment)
+	}
+	s.writeRegister("ip", bootOffset)
+	s.writeRegister("sp", bootOffset)
+	s.writeRegister("dx", 0)
+	s.runUntilTerminated()
+	return nil
+}
+
+// int13 dispatches a disk service on AH. Only drive 0 exists.
+func (s *Simulator) int13() error {
+	function := byte(s.readRegister("ah"))
+	f := s.floppy
+	if f == nil || byte(s.readRegister("dl")) != 0 {
+		s.diskResult(f, diskTimeout)
+		return nil
+	}
+	switch function {
+	case 0x00:
+		s.diskResult(f, diskOK)
+	case 0x01:
+		// report the last status without replacing it, so asking again gives the same answer
+		s.writeRegister("ah", uint16(f.status))
+		s.setReturnFlag("C", f.status != diskOK)
+	case 0x02, 0x03:
+		s.transferSectors(f, function == 0x03)
+	case 0x08:
+		s.writeRegister("bl", uint16(f.driveType))
+		s.writeRegister("ch", uint16(f.cylinders-1))
+		s.writeRegister("cl", uint16(f.sectors)|uint16((f.cylinders-1)>>8)<<6)
+		s.writeRegister("dh", uint16(f.heads-1))
+		s.writeRegister("dl", 1)
+		s.diskResult(f, diskOK)
+	default:
+		s.logger.Printf("unsupported INT 13h function 0x%02x", function)
+		s.diskResult(f, diskBadCommand)
+	}
+	return nil
+}
+
+// transferSectors reads or writes AL sectors starting at the CHS address in CX and DH to or from ES:BX,
+// returning the number transferred in AL.
+func (s *Simulator) transferSectors(f *floppy, write bool) {
+	count := int(s.readRegister("al"))
+	cx := s.readRegister("cx")
+	cylinder := int(cx>>8) | int(cx&0xC0)<<2
+	sector := int(cx & 0x3F)
+	head := int(s.readRegister("dh"))
+	if sector == 0 || sector > f.sectors || head >= f.heads || cylinder >= f.cylinders {
+		s.writeRegister("al", 0)
+		s.diskResult(f, diskSectorNotFound)
+		return
+	}
+
+	es := s.readRegister("es")
+	bx := s.readRegister("bx")
+	lba := (cylinder*f.heads+head)*f.sectors + sector - 1
+	buffer := make([]byte, sectorSize)
+	for i := range count {
+		if lba+i >= f.cylinders*f.heads*f.sectors {
+			s.writeRegister("al", uint16(i))
+			s.diskResult(f, diskSectorNotFound)
+			return
+		}
+		offset := int64(lba+i) * sectorSize
+		base := bx + uint16(i*sectorSize)
+		if write {
+			for j := range buffer {
+				buffer[j] = s.Memory.Read8(memory.Address(es, base+uint16(j)))
+			}
+			if _, err := f.disk.WriteAt(buffer, offset); err != nil {
+				s.writeRegister("al", uint16(i))
+				s.diskResult(f, diskWriteFault)
+				return
+			}
+			continue
+		}
+		if _, err := f.disk.ReadAt(buffer, offset); err != nil {
+			s.writeRegister("al", uint16(i))
+			s.diskResult(f, diskSectorNotFound)
+			return
+		}
+		for j, b := range buffer {
+			s.Memory.Write8(memory.Address(es, base+uint16(j)), b)
+		}
+	}
+	s.diskResult(f, diskOK)
+}
+
+// diskResult reports a status in AH and CF and remembers it for function 01h.
+func (s *Simulator) diskResult(f *floppy, status byte) {
+	if f != nil {
+		f.status = status
+	}
+	s.writeRegister("ah", uint16(status))
+	s.setReturnFlag("C", status != diskOK)
+}
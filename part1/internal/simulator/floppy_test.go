@@ -0,0 +1,111 @@
+package simulator
+
+import (
+	"testing"
+)
+
+// memoryDisk is a floppy image held in memory.
+type memoryDisk []byte
+
+func (d memoryDisk) ReadAt(p []byte, off int64) (int, error) {
+	return copy(p, d[off:]), nil
+}
+
+func (d memoryDisk) WriteAt(p []byte, off int64) (int, error) {
+	return copy(d[off:], p), nil
+}
+
+func TestSimulatorBootFloppy(t *testing.T) {
+	image := make(memoryDisk, 368640)
+	copy(image, []byte{
+		0xb8, 0x01, 0x02, // mov ax, 0x0201
+		0xb9, 0x02, 0x00, // mov cx, 2
+		0xba, 0x00, 0x00, // mov dx, 0
+		0xbb, 0x00, 0x80, // mov bx, 0x8000
+		0xcd, 0x13, // int 19
+		0x8b, 0x36, 0x00, 0x80, // mov si, [0x8000]
+		0xb8, 0x01, 0x03, // mov ax, 0x0301
+		0xb9, 0x09, 0x01, // mov cx, 0x0109
+		0xba, 0x00, 0x01, // mov dx, 0x0100
+		0xcd, 0x13, // int 19
+		0xb8, 0x01, 0x02, // mov ax, 0x0201
+		0xb9, 0x0a, 0x00, // mov cx, 10
+		0xcd, 0x13, // int 19
+		0x9c, // pushf
+		0x5f, // pop di
+		0xf4, // hlt
+	})
+	image[510], image[511] = 0x55, 0xaa
+	image[sectorSize], image[sectorSize+1] = 0x34, 0x12
+
+	sim := NewSimulator(false)
+	if err := sim.BootFloppy(image, int64(len(image))); err != nil {
+		t.Fatalf("Error booting: %v", err)
+	}
+	if _, err := sim.Run(); err != nil {
+		t.Fatalf("Error running instructions: %v", err)
+	}
+
+	if !sim.Halted() {
+		t.Fatalf("Expected the boot sector to halt")
+	}
+	if si := sim.readRegister("si"); si != 0x1234 {
+		t.Fatalf("Expected si to be 0x1234 but got 0x%x", si)
+	}
+	// cylinder 1, head 1, sector 9 is the 36th sector
+	if written := image[35*sectorSize : 35*sectorSize+2]; written[0] != 0x34 || written[1] != 0x12 {
+		t.Fatalf("Expected the written sector to start with 34 12 but got % x", written)
+	}
+	// sector 10 does not exist on a 360K disk
+	if ax := sim.readRegister("ax"); ax != diskSectorNotFound<<8 {
+		t.Fatalf("Expected ax to be 0x%x but got 0x%x", diskSectorNotFound<<8, ax)
+	}
+	if di := sim.readRegister("di"); di&flagBits["C"] == 0 {
+		t.Fatalf("Expected CF to be set after the failed read")
+	}
+}
+
+func TestSimulatorBootFloppyUnknownSize(t *testing.T) {
+	sim := NewSimulator(false)
+	if err := sim.BootFloppy(make(memoryDisk, 1000), 1000); err == nil {
+		t.Fatalf("Expected an error for an image that is not a floppy format")
+	}
+}
+
+func TestSimulatorFloppyLastStatus(t *testing.T) {
+	image := make(memoryDisk, 368640)
+	copy(image, []byte{
+		0xb8, 0x01, 0x02, // mov ax, 0x0201
+		0xb9, 0x0a, 0x00, // mov cx, 10
+		0xba, 0x00, 0x00, // mov dx, 0
+		0xbb, 0x00, 0x80, // mov bx, 0x8000
+		0xcd, 0x13, // int 19
+		0xb4, 0x01, // mov ah, 1
+		0xcd, 0x13, // int 19
+		0x89, 0xc6, // mov si, ax
+		0xb4, 0x01, // mov ah, 1
+		0xcd, 0x13, // int 19
+		0x9c, // pushf
+		0x5f, // pop di
+		0xf4, // hlt
+	})
+	image[510], image[511] = 0x55, 0xaa
+
+	sim := NewSimulator(false)
+	if err := sim.BootFloppy(image, int64(len(image))); err != nil {
+		t.Fatalf("Error booting: %v", err)
+	}
+	if _, err := sim.Run(); err != nil {
+		t.Fatalf("Error running instructions: %v", err)
+	}
+
+	// both status requests report the failed read of sector 10
+	for _, register := range []string{"si", "ax"} {
+		if ah := sim.readRegister(register) >> 8; ah != diskSectorNotFound {
+			t.Fatalf("Expected the status in %s to be 0x%x but got 0x%x", register, diskSectorNotFound, ah)
+		}
+	}
+	if di := sim.readRegister("di"); di&flagBits["C"] == 0 {
+		t.Fatalf("Expected CF to be set for a non-zero status")
+	}
+}
@@ -0,0 +1,141 @@
+package simulator
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+
+	"github.com/8086-simulator/part1/internal/memory"
+)
+
+// PixelFormat is the layout of the pixels in a framebuffer.
+type PixelFormat int
+
+const (
+	// PixelIndexed8 is one byte per pixel indexing the default VGA palette.
+	PixelIndexed8 PixelFormat = iota
+	// PixelRGBA32 is four bytes per pixel in red, green, blue, alpha order.
+	PixelRGBA32
+)
+
+// bytesPerPixel returns the size of one pixel.
+func (f PixelFormat) bytesPerPixel() int {
+	if f == PixelRGBA32 {
+		return 4
+	}
+	return 1
+}
+
+// Framebuffer describes an image stored row by row in memory starting at a physical address.
+type Framebuffer struct {
+	Address uint32
+	Width   int
+	Height  int
+	Format  PixelFormat
+}
+
+// Image reads the framebuffer out of memory.
+func (s *Simulator) Image(fb Framebuffer) (image.Image, error) {
+	if fb.Width <= 0 || fb.Height <= 0 {
+		return nil, fmt.Errorf("invalid framebuffer size %dx%d", fb.Width, fb.Height)
+	}
+	if size := fb.Width * fb.Height * fb.Format.bytesPerPixel(); int(fb.Address)+size > memory.Size {
+		return nil, fmt.Errorf("framebuffer of %d bytes at 0x%x runs past the end of memory", size, fb.Address)
+	}
+
+	bounds := image.Rect(0, 0, fb.Width, fb.Height)
+	switch fb.Format {
+	case PixelIndexed8:
+		img := image.NewPaletted(bounds, vgaPalette)
+		for i := range img.Pix {
+			img.Pix[i] = s.Memory.Read8(fb.Address + uint32(i))
+		}
+		return img, nil
+	case PixelRGBA32:
+		img := image.NewNRGBA(bounds)
+		for i := range img.Pix {
+			img.Pix[i] = s.Memory.Read8(fb.Address + uint32(i))
+		}
+		return img, nil
+	default:
+		return nil, fmt.Errorf("unknown pixel format %d", fb.Format)
+	}
+}
+
+// ExportPNG writes the framebuffer as a PNG image. It can be called between steps as well as after a run.
+func (s *Simulator) ExportPNG(w io.Writer, fb Framebuffer) error {
+	img, err := s.Image(fb)
+	if err != nil {
+		return err
+	}
+	return png.Encode(w, img)
+}
+
+// vgaPalette is the palette the VGA BIOS loads for mode 13h: the 16 EGA colors, 16 shades of gray,
+// a color wheel of 24 hues at three intensities and three saturations, and 8 black entries.
+var vgaPalette = newVGAPalette()
+
+func newVGAPalette() color.Palette {
+	// vga scales a 6-bit DAC value to 8 bits.
+	vga := func(r, g, b byte) color.Color {
+		scale := func(v byte) byte { return v<<2 | v>>4 }
+		return color.RGBA{scale(r), scale(g), scale(b), 0xFF}
+	}
+
+	var palette color.Palette
+	for i := range byte(16) {
+		// EGA colors: bit 0 blue, bit 1 green, bit 2 red, bit 3 intensity; color 6 is brown
+		level := func(bit byte) byte {
+			v := byte(0)
+			if i&bit != 0 {
+				v += 42
+			}
+			if i&8 != 0 {
+				v += 21
+			}
+			return v
+		}
+		r, g, b := level(4), level(2), level(1)
+		if i == 6 {
+			g = 21
+		}
+		palette = append(palette, vga(r, g, b))
+	}
+	for _, v := range []byte{0, 5, 8, 11, 14, 17, 20, 24, 28, 32, 36, 40, 45, 50, 56, 63} {
+		palette = append(palette, vga(v, v, v))
+	}
+
+	// each group lists the low value, three steps and the high value of a hue wheel
+	groups := [][5]byte{
+		{0, 16, 31, 47, 63}, {31, 39, 47, 55, 63}, {45, 49, 54, 58, 63},
+		{0, 7, 14, 21, 28}, {14, 17, 21, 24, 28}, {20, 22, 24, 26, 28},
+		{0, 4, 8, 12, 16}, {8, 10, 12, 14, 16}, {11, 12, 13, 15, 16},
+	}
+	for _, g := range groups {
+		lo, hi := g[0], g[4]
+		for i := 0; i < 4; i++ {
+			palette = append(palette, vga(g[i], lo, hi)) // blue to magenta
+		}
+		for i := 4; i > 0; i-- {
+			palette = append(palette, vga(hi, lo, g[i])) // magenta to red
+		}
+		for i := 0; i < 4; i++ {
+			palette = append(palette, vga(hi, g[i], lo)) // red to yellow
+		}
+		for i := 4; i > 0; i-- {
+			palette = append(palette, vga(g[i], hi, lo)) // yellow to green
+		}
+		for i := 0; i < 4; i++ {
+			palette = append(palette, vga(lo, hi, g[i])) // green to cyan
+		}
+		for i := 4; i > 0; i-- {
+			palette = append(palette, vga(lo, g[i], hi)) // cyan to blue
+		}
+	}
+	for len(palette) < 256 {
+		palette = append(palette, vga(0, 0, 0))
+	}
+	return palette
+}
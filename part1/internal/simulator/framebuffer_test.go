@@ -0,0 +1,105 @@
+package simulator
+
+import (
+	"bytes"
+	"image/color"
+	"image/png"
+	"testing"
+)
+
+func TestSimulatorExportPNG(t *testing.T) {
+	tests := []struct {
+		name     string
+		pixels   []byte
+		format   PixelFormat
+		expected []color.NRGBA
+	}{
+		{
+			name:     "rgba",
+			pixels:   []byte{0xff, 0x00, 0x00, 0xff, 0x10, 0x20, 0x30, 0xff},
+			format:   PixelRGBA32,
+			expected: []color.NRGBA{{0xff, 0x00, 0x00, 0xff}, {0x10, 0x20, 0x30, 0xff}},
+		},
+		{
+			name:   "indexed with the vga palette",
+			pixels: []byte{1, 6, 31, 40},
+			format: PixelIndexed8,
+			// blue, brown, white and the high intensity red of the color wheel
+			expected: []color.NRGBA{{0x00, 0x00, 0xaa, 0xff}, {0xaa, 0x55, 0x00, 0xff}, {0xff, 0xff, 0xff, 0xff}, {0xff, 0x00, 0x00, 0xff}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sim := NewSimulator(false)
+			sim.Memory.Load(0x100, tt.pixels)
+			fb := Framebuffer{Address: 0x100, Width: len(tt.expected), Height: 1, Format: tt.format}
+			var out bytes.Buffer
+			if err := sim.ExportPNG(&out, fb); err != nil {
+				t.Fatalf("Error exporting: %v", err)
+			}
+
+			img, err := png.Decode(&out)
+			if err != nil {
+				t.Fatalf("Error decoding the PNG: %v", err)
+			}
+			for x, expected := range tt.expected {
+				if got := color.NRGBAModel.Convert(img.At(x, 0)); got != expected {
+					t.Fatalf("Expected pixel %d to be %v but got %v", x, expected, got)
+				}
+			}
+		})
+	}
+}
+
+func TestSimulatorExportPNGBounds(t *testing.T) {
+	sim := NewSimulator(false)
+	var out bytes.Buffer
+	if err := sim.ExportPNG(&out, Framebuffer{Address: 0xFFFF0, Width: 8, Height: 8, Format: PixelRGBA32}); err == nil {
+		t.Fatalf("Expected an error for a framebuffer past the end of memory")
+	}
+	if len(vgaPalette) != 256 {
+		t.Fatalf("Expected 256 palette entries but got %d", len(vgaPalette))
+	}
+}
+
+func TestSimulatorExportPNGAtBreakpoint(t *testing.T) {
+	sim := NewSimulator(false)
+	sim.Load([]byte{
+		0xb0, 0x01, // mov al, 1
+		0xa2, 0x00, 0x02, // mov [512], al
+		0xb0, 0x02, // mov al, 2 (breakpoint)
+		0xa2, 0x00, 0x02, // mov [512], al
+		0xf4, // hlt
+	})
+	fb := Framebuffer{Address: 0x200, Width: 1, Height: 1, Format: PixelIndexed8}
+	var snapshot bytes.Buffer
+	hits := 0
+	sim.SetBreakpoint(5, func() error {
+		hits++
+		return sim.ExportPNG(&snapshot, fb)
+	})
+	sim.SetBreakpoint(7, func() error {
+		t.Fatalf("Expected a cleared breakpoint not to be called")
+		return nil
+	})
+	sim.SetBreakpoint(7, nil)
+	if _, err := sim.Run(); err != nil {
+		t.Fatalf("Error running instructions: %v", err)
+	}
+
+	if hits != 1 {
+		t.Fatalf("Expected the breakpoint to be hit once but got %d", hits)
+	}
+	img, err := png.Decode(&snapshot)
+	if err != nil {
+		t.Fatalf("Error decoding the PNG: %v", err)
+	}
+	// the first pixel value, blue, before the second store replaces it with green
+	if got, expected := color.NRGBAModel.Convert(img.At(0, 0)), (color.NRGBA{0x00, 0x00, 0xaa, 0xff}); got != expected {
+		t.Fatalf("Expected the snapshot pixel to be %v but got %v", expected, got)
+	}
+	if pixel := sim.Memory.Read8(0x200); pixel != 2 {
+		t.Fatalf("Expected the pixel to be 2 after the run but got %d", pixel)
+	}
+}
@@ -0,0 +1,99 @@
+package simulator
+
+import (
+	"fmt"
+
+	"github.com/8086-simulator/part1/internal/memory"
+)
+
+// handlerSegment holds one IRET stub per interrupt vector. Vector n points at handlerSegment:n, and
+// reaching a stub runs the built-in handler for n, if any, before the IRET returns to the caller.
+// Programs can still hook a vector and chain to the previous one like they would with a real BIOS.
+const handlerSegment uint16 = 0xF000
+
+// iretOpcode fills the handler stubs.
+const iretOpcode = 0xCF
+
+// interruptHandler is a built-in service routine written in Go. It works on the registers and memory
+// as the program left them when it executed INT. An error means the service cannot complete and ends
+// the run.
+type interruptHandler func() error
+
+// installHandlers points every interrupt vector at its stub. Vectors without a built-in handler
+// simply return.
+func (s *Simulator) installHandlers() {
+	for vector := range 256 {
+		s.Memory.Write8(memory.Address(handlerSegment, uint16(vector)), iretOpcode)
+		s.Memory.Write16(memory.Address(0, uint16(vector)*4), uint16(vector))
+		s.Memory.Write16(memory.Address(0, uint16(vector)*4+2), handlerSegment)
+	}
+	s.handlersInstalled = true
+	s.handlers = map[byte]interruptHandler{
+		0x10: s.int10,
+		0x13: s.int13,
+		0x16: s.int16,
+		0x1A: s.int1A,
+		0x20: s.int20,
+		0x21: s.int21,
+	}
+	s.initBIOSData()
+}
+
+// pendingHandler returns the vector whose stub CS:IP points at.
+func (s *Simulator) pendingHandler() (byte, bool) {
+	if !s.handlersInstalled || s.readRegister("cs") != handlerSegment || s.readRegister("ip") > 0xFF {
+		return 0, false
+	}
+	return byte(s.readRegister("ip")), true
+}
+
+// runHandler runs the built-in handler for a vector. The stub's IRET executes on the next step.
+func (s *Simulator) runHandler(vector byte) (*Result, error) {
+	registersPrevVal := s.snapshotRegisters()
+	flagsPrevVal := s.printFlags()
+	ipPrevVal := s.readRegister("ip")
+	s.instructionBus = BusStats{}
+	s.portTrace = ""
+
+	if handler, ok := s.handlers[vector]; ok {
+		if err := handler(); err != nil {
+			return nil, err
+		}
+	}
+	s.handlerDone = true
+
+	result := &Result{Bus: s.instructionBus}
+	s.bus.add(s.instructionBus)
+	text := fmt.Sprintf("(int %d handler) ;", vector)
+	result.Text = text + s.traceChanges(registersPrevVal, flagsPrevVal, ipPrevVal)
+	return result, nil
+}
+
+// setReturnFlag changes a flag in the FLAGS word the interrupt pushed, so the handler's IRET returns it
+// to the caller.
+func (s *Simulator) setReturnFlag(flag string, value bool) {
+	addr := memory.Address(s.readRegister("ss"), s.readRegister("sp")+4)
+	word := s.Memory.Read16(addr)
+	if value {
+		word |= flagBits[flag]
+	} else {
+		word &^= flagBits[flag]
+	}
+	s.Memory.Write16(addr, word)
+}
+
+// terminate ends the program with an exit code, as DOS does for INT 20h and INT 21h function 4Ch.
+func (s *Simulator) terminate(code byte) {
+	s.terminated = true
+	s.exitCode = code
+}
+
+// Terminated reports whether the program exited through DOS.
+func (s *Simulator) Terminated() bool {
+	return s.terminated
+}
+
+// ExitCode returns the code the program passed to DOS when it terminated.
+func (s *Simulator) ExitCode() byte {
+	return s.exitCode
+}
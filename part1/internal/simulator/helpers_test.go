@@ -0,0 +1,56 @@
+package simulator
+
+import "encoding/binary"
+
+// Program and file builders shared by the tests.
+
+// interruptProgram installs handler for the given vector, runs body and halts. The program is meant
+// to be loaded at 0100:0000; the vector must be below 64 so its IVT offset fits in a byte.
+func interruptProgram(vector byte, body, handler []byte) []byte {
+	program := []byte{
+		0xc7, 0x06, vector * 4, 0x00, 0x00, 0x00, // mov word [vector * 4], handler
+		0xc7, 0x06, vector*4 + 2, 0x00, 0x00, 0x01, // mov word [vector * 4 + 2], 0x100
+		0xbc, 0x00, 0x08, // mov sp, 0x800
+	}
+	program = append(program, body...)
+	program = append(program, 0xf4) // hlt
+	program[4] = byte(len(program))
+	return append(program, handler...)
+}
+
+// pitProgram loads a PIT channel through ports 43h and 40h-42h with the given control byte and a
+// low/high byte count.
+func pitProgram(channel, control byte, count uint16) []byte {
+	port := 0x40 + channel
+	return []byte{
+		0xb0, control, // mov al, control
+		0xe6, 0x43, // out 67, al
+		0xb0, byte(count), // mov al, count & 0xff
+		0xe6, port, // out port, al
+		0xb0, byte(count >> 8), // mov al, count >> 8
+		0xe6, port, // out port, al
+	}
+}
+
+// mzFile wraps code in an MZ executable with a two-paragraph header, a stack segment following the code
+// and the given relocations, each an offset into the code segment.
+func mzFile(code []byte, minAlloc, maxAlloc uint16, relocations ...uint16) []byte {
+	header := make([]byte, 32)
+	size := len(header) + len(code)
+	header[0], header[1] = 'M', 'Z'
+	binary.LittleEndian.PutUint16(header[0x02:], uint16(size%mzPageSize))
+	binary.LittleEndian.PutUint16(header[0x04:], uint16((size+mzPageSize-1)/mzPageSize))
+	binary.LittleEndian.PutUint16(header[0x06:], uint16(len(relocations)))
+	binary.LittleEndian.PutUint16(header[0x08:], 2)
+	binary.LittleEndian.PutUint16(header[0x0A:], minAlloc)
+	binary.LittleEndian.PutUint16(header[0x0C:], maxAlloc)
+	binary.LittleEndian.PutUint16(header[0x0E:], 0x10)  // ss
+	binary.LittleEndian.PutUint16(header[0x10:], 0x100) // sp
+	binary.LittleEndian.PutUint16(header[0x14:], 0)     // ip
+	binary.LittleEndian.PutUint16(header[0x16:], 0)     // cs
+	binary.LittleEndian.PutUint16(header[0x18:], 0x1C)
+	for i, offset := range relocations {
+		binary.LittleEndian.PutUint16(header[0x1C+i*4:], offset)
+	}
+	return append(header, code...)
+}
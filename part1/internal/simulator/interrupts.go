@@ -0,0 +1,50 @@
+package simulator
+
+import (
+	"github.com/8086-simulator/part1/internal/instruction"
+)
+
+const (
+	// DivideErrorInterrupt is raised by DIV and IDIV on a zero divisor or a quotient that does not fit.
+	DivideErrorInterrupt byte = 0
+	// SingleStepInterrupt is raised after every instruction that starts with TF set.
+	SingleStepInterrupt byte = 1
+	// BreakpointInterrupt is raised by the one-byte INT3 instruction.
+	BreakpointInterrupt byte = 3
+	// OverflowInterrupt is raised by INTO when OF is set.
+	OverflowInterrupt byte = 4
+)
+
+// interrupt pushes FLAGS, CS and IP, clears IF and TF and jumps through the interrupt vector table at 0000:0000.
+func (s *Simulator) interrupt(n byte) {
+	s.push(s.flagsWord())
+	s.flags["I"] = false
+	s.flags["T"] = false
+	s.push(s.readRegister("cs"))
+	s.push(s.readRegister("ip"))
+
+	vector := uint16(n) * 4
+	s.writeRegister("ip", s.busRead(0, vector, true))
+	s.writeRegister("cs", s.busRead(0, vector+2, true))
+}
+
+// doInterrupt executes INT, INT3 and INTO.
+func (s *Simulator) doInterrupt(ins *instruction.Instruction) {
+	switch ins.Op {
+	case instruction.INT:
+		s.interrupt(byte(ins.Immediate.Value))
+	case instruction.INT3:
+		s.interrupt(BreakpointInterrupt)
+	case instruction.INTO:
+		if s.flags["O"] {
+			s.interrupt(OverflowInterrupt)
+		}
+	}
+}
+
+// doIret returns from an interrupt handler, restoring IP, CS and FLAGS in the reverse order they were pushed.
+func (s *Simulator) doIret() {
+	s.writeRegister("ip", s.pop())
+	s.writeRegister("cs", s.pop())
+	s.setFlagsWord(s.pop())
+}
@@ -0,0 +1,98 @@
+package simulator
+
+import "testing"
+
+func TestSimulatorInterrupts(t *testing.T) {
+	tests := []struct {
+		name       string
+		vector     byte
+		body       []byte
+		handler    []byte
+		expected   map[string]uint16
+		expectedIF bool
+	}{
+		{
+			name:   "int and iret",
+			vector: 0x21,
+			body: []byte{
+				0xfb,       // sti
+				0xcd, 0x21, // int 33
+				0xbb, 0x01, 0x00, // mov bx, 1
+			},
+			handler: []byte{
+				0x9c,             // pushf
+				0x5a,             // pop dx
+				0xb9, 0x55, 0x00, // mov cx, 0x55
+				0xcf, // iret
+			},
+			// the handler runs with IF cleared and IRET restores it
+			expected:   map[string]uint16{"bx": 1, "cx": 0x55, "dx": 0xf002, "sp": 0x800},
+			expectedIF: true,
+		},
+		{
+			name:     "int3",
+			vector:   BreakpointInterrupt,
+			body:     []byte{0xcc},                   // int3
+			handler:  []byte{0xb9, 0x55, 0x00, 0xcf}, // mov cx, 0x55; iret
+			expected: map[string]uint16{"cx": 0x55, "sp": 0x800},
+		},
+		{
+			name:     "into with overflow",
+			vector:   OverflowInterrupt,
+			body:     []byte{0xb0, 0x7f, 0x04, 0x01, 0xce}, // mov al, 0x7f; add al, 1; into
+			handler:  []byte{0xb9, 0x55, 0x00, 0xcf},       // mov cx, 0x55; iret
+			expected: map[string]uint16{"cx": 0x55, "sp": 0x800},
+		},
+		{
+			name:     "into without overflow",
+			vector:   OverflowInterrupt,
+			body:     []byte{0xb0, 0x01, 0x04, 0x01, 0xce}, // mov al, 1; add al, 1; into
+			handler:  []byte{0xb9, 0x55, 0x00, 0xcf},       // mov cx, 0x55; iret
+			expected: map[string]uint16{"cx": 0, "sp": 0x800},
+		},
+		{
+			name:   "single step",
+			vector: SingleStepInterrupt,
+			body: []byte{
+				0x9c,             // pushf
+				0x58,             // pop ax
+				0x0d, 0x00, 0x01, // or ax, 0x100
+				0x50,             // push ax
+				0x9d,             // popf
+				0xbb, 0x01, 0x00, // mov bx, 1
+				0xbb, 0x02, 0x00, // mov bx, 2
+				0x9c,             // pushf
+				0x58,             // pop ax
+				0x25, 0xff, 0xfe, // and ax, 0xfeff
+				0x50, // push ax
+				0x9d, // popf
+			},
+			handler: []byte{0x83, 0xc7, 0x01, 0xcf}, // add di, 1; iret
+			// every instruction from the one after the first popf up to the second popf traps once
+			expected: map[string]uint16{"bx": 2, "di": 7, "sp": 0x800},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sim := NewSimulator(false)
+			sim.writeRegister("cs", 0x100)
+			sim.Load(interruptProgram(tt.vector, tt.body, tt.handler))
+			if _, err := sim.Run(); err != nil {
+				t.Fatalf("Error running instructions: %v", err)
+			}
+
+			if !sim.Halted() {
+				t.Fatalf("Expected the program to halt")
+			}
+			for register, expected := range tt.expected {
+				if got := sim.readRegister(register); got != expected {
+					t.Fatalf("Expected %s to be 0x%x but got 0x%x", register, expected, got)
+				}
+			}
+			if sim.flags["I"] != tt.expectedIF {
+				t.Fatalf("Expected IF to be %t but got %t", tt.expectedIF, sim.flags["I"])
+			}
+		})
+	}
+}
@@ -0,0 +1,81 @@
+package simulator
+
+import (
+	"fmt"
+
+	"github.com/8086-simulator/part1/internal/instruction"
+)
+
+// doJump executes a short conditional jump or one of the LOOP/JCXZ instructions.
+func (s *Simulator) doJump(ins *instruction.Instruction) error {
+	var taken bool
+	switch ins.Op {
+	case instruction.LOOP, instruction.LOOPZ, instruction.LOOPNZ:
+		cx := s.readRegister("cx") - 1
+		s.writeRegister("cx", cx)
+		taken = cx != 0
+		if ins.Op == instruction.LOOPZ {
+			taken = taken && s.flags["Z"]
+		} else if ins.Op == instruction.LOOPNZ {
+			taken = taken && !s.flags["Z"]
+		}
+	case instruction.JCXZ:
+		taken = s.readRegister("cx") == 0
+	default:
+		condition, err := s.jumpCondition(ins.Op)
+		if err != nil {
+			return err
+		}
+		taken = condition
+	}
+
+	s.branchTaken = taken
+	if taken {
+		s.jump(ins)
+	}
+	return nil
+}
+
+// jumpCondition evaluates the flag condition tested by a conditional jump.
+func (s *Simulator) jumpCondition(op instruction.Op) (bool, error) {
+	switch op {
+	case instruction.JO:
+		return s.flags["O"], nil
+	case instruction.JNO:
+		return !s.flags["O"], nil
+	case instruction.JB:
+		return s.flags["C"], nil
+	case instruction.JNB:
+		return !s.flags["C"], nil
+	case instruction.JE:
+		return s.flags["Z"], nil
+	case instruction.JNZ, instruction.JNE:
+		return !s.flags["Z"], nil
+	case instruction.JBE:
+		return s.flags["C"] || s.flags["Z"], nil
+	case instruction.JA:
+		return !s.flags["C"] && !s.flags["Z"], nil
+	case instruction.JS:
+		return s.flags["S"], nil
+	case instruction.JNS:
+		return !s.flags["S"], nil
+	case instruction.JP:
+		return s.flags["P"], nil
+	case instruction.JNP:
+		return !s.flags["P"], nil
+	case instruction.JL:
+		return s.flags["S"] != s.flags["O"], nil
+	case instruction.JNL:
+		return s.flags["S"] == s.flags["O"], nil
+	case instruction.JLE:
+		return s.flags["Z"] || s.flags["S"] != s.flags["O"], nil
+	case instruction.JG:
+		return !s.flags["Z"] && s.flags["S"] == s.flags["O"], nil
+	}
+	return false, fmt.Errorf("unsupported jump: %s", op)
+}
+
+// jump moves IP by the instruction's signed displacement.
+func (s *Simulator) jump(ins *instruction.Instruction) {
+	s.writeRegister("ip", uint16(ins.IPRegister+ins.Immediate.Value))
+}
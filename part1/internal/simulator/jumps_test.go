@@ -0,0 +1,139 @@
+package simulator
+
+import (
+	"testing"
+)
+
+func TestSimulatorConditionalJumps(t *testing.T) {
+	tests := []struct {
+		name   string
+		opCode byte
+		a      uint16
+		b      uint16
+		taken  bool
+	}{
+		{"jo overflow", 0x70, 0x8000, 1, true},
+		{"jo no overflow", 0x70, 5, 1, false},
+		{"jno", 0x71, 5, 5, true},
+		{"jb below", 0x72, 1, 0xffff, true},
+		{"jb above", 0x72, 0xffff, 1, false},
+		{"jnb above", 0x73, 0xffff, 1, true},
+		{"jnb below", 0x73, 1, 0xffff, false},
+		{"je equal", 0x74, 5, 5, true},
+		{"je not equal", 0x74, 5, 6, false},
+		{"jne not equal", 0x75, 5, 6, true},
+		{"jne equal", 0x75, 5, 5, false},
+		{"jbe equal", 0x76, 5, 5, true},
+		{"jbe above", 0x76, 6, 5, false},
+		{"ja above", 0x77, 0xffff, 1, true},
+		{"ja equal", 0x77, 5, 5, false},
+		{"js negative", 0x78, 1, 2, true},
+		{"js positive", 0x78, 2, 1, false},
+		{"jns positive", 0x79, 2, 1, true},
+		{"jp even parity", 0x7a, 3, 0, true},
+		{"jp odd parity", 0x7a, 1, 0, false},
+		{"jnp odd parity", 0x7b, 1, 0, true},
+		{"jl less", 0x7c, 0xffff, 1, true},
+		{"jl greater", 0x7c, 1, 0xffff, false},
+		{"jl overflow", 0x7c, 0x8000, 1, true},
+		{"jnl equal", 0x7d, 5, 5, true},
+		{"jnl less", 0x7d, 0xffff, 1, false},
+		{"jle equal", 0x7e, 5, 5, true},
+		{"jle greater", 0x7e, 1, 0xffff, false},
+		{"jg greater", 0x7f, 1, 0xffff, true},
+		{"jg equal", 0x7f, 5, 5, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sim := NewSimulator(false)
+			sim.Load([]byte{
+				0xb8, byte(tt.a), byte(tt.a >> 8), // mov ax, a
+				0xbb, byte(tt.b), byte(tt.b >> 8), // mov bx, b
+				0x39, 0xd8, // cmp ax, bx
+				tt.opCode, 0x03, // jump over the next instruction
+				0xb9, 0x01, 0x00, // mov cx, 1
+			})
+			if _, err := sim.Run(); err != nil {
+				t.Fatalf("Error running instructions: %v", err)
+			}
+
+			expectedCX := uint16(1)
+			if tt.taken {
+				expectedCX = 0
+			}
+			if cx := sim.readRegister("cx"); cx != expectedCX {
+				t.Fatalf("Expected jump taken to be %t but cx is %d", tt.taken, cx)
+			}
+		})
+	}
+}
+
+func TestSimulatorLoops(t *testing.T) {
+	tests := []struct {
+		name       string
+		program    []byte
+		expectedBX uint16
+		expectedCX uint16
+	}{
+		{
+			// mov cx, 3; label: add bx, 1; loop label
+			name:       "loop",
+			program:    []byte{0xb9, 0x03, 0x00, 0x83, 0xc3, 0x01, 0xe2, 0xfb},
+			expectedBX: 3,
+			expectedCX: 0,
+		},
+		{
+			// mov cx, 5; label: add bx, 1; cmp bx, 1; loopz label
+			name:       "loopz",
+			program:    []byte{0xb9, 0x05, 0x00, 0x83, 0xc3, 0x01, 0x83, 0xfb, 0x01, 0xe1, 0xf8},
+			expectedBX: 2,
+			expectedCX: 3,
+		},
+		{
+			// mov cx, 5; label: add bx, 1; cmp bx, 2; loopnz label
+			name:       "loopnz",
+			program:    []byte{0xb9, 0x05, 0x00, 0x83, 0xc3, 0x01, 0x83, 0xfb, 0x02, 0xe0, 0xf8},
+			expectedBX: 2,
+			expectedCX: 3,
+		},
+		{
+			// mov cx, 1; label: add bx, 1; loop label (cx reaches zero on the first pass)
+			name:       "loop single pass",
+			program:    []byte{0xb9, 0x01, 0x00, 0x83, 0xc3, 0x01, 0xe2, 0xfb},
+			expectedBX: 1,
+			expectedCX: 0,
+		},
+		{
+			// jcxz skip; mov bx, 1; skip:
+			name:       "jcxz zero",
+			program:    []byte{0xe3, 0x03, 0xbb, 0x01, 0x00},
+			expectedBX: 0,
+			expectedCX: 0,
+		},
+		{
+			// mov cx, 2; jcxz skip; mov bx, 1; skip:
+			name:       "jcxz non-zero",
+			program:    []byte{0xb9, 0x02, 0x00, 0xe3, 0x03, 0xbb, 0x01, 0x00},
+			expectedBX: 1,
+			expectedCX: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sim := NewSimulator(false)
+			sim.Load(tt.program)
+			if _, err := sim.Run(); err != nil {
+				t.Fatalf("Error running instructions: %v", err)
+			}
+
+			if bx := sim.readRegister("bx"); bx != tt.expectedBX {
+				t.Fatalf("Expected bx to be %d but got %d", tt.expectedBX, bx)
+			}
+			if cx := sim.readRegister("cx"); cx != tt.expectedCX {
+				t.Fatalf("Expected cx to be %d but got %d", tt.expectedCX, cx)
+			}
+		})
+	}
+}
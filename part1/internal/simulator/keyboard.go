@@ -0,0 +1,41 @@
+package simulator
+
+// scanCodes maps the characters of a US keyboard to their scan codes.
+var scanCodes = map[byte]byte{
+	0x1B: 0x01, '\b': 0x0E, '\t': 0x0F, '\r': 0x1C, ' ': 0x39,
+}
+
+func init() {
+	rows := []struct {
+		first          byte
+		plain, shifted string
+	}{
+		{0x02, "1234567890-=", "!@#$%^&*()_+"},
+		{0x10, "qwertyuiop[]", "QWERTYUIOP{}"},
+		{0x1E, "asdfghjkl;'`", "ASDFGHJKL:\"~"},
+		{0x2B, "\\zxcvbnm,./", "|ZXCVBNM<>?"},
+	}
+	for _, row := range rows {
+		for i := range len(row.plain) {
+			scanCodes[row.plain[i]] = row.first + byte(i)
+			scanCodes[row.shifted[i]] = row.first + byte(i)
+		}
+	}
+}
+
+// QueueKey appends a keystroke for INT 16h to return, given its scan code and ASCII value. Keys
+// without a character, such as the arrows, have an ASCII value of 0.
+func (s *Simulator) QueueKey(scanCode, ascii byte) {
+	s.keys = append(s.keys, uint16(scanCode)<<8|uint16(ascii))
+}
+
+// QueueKeys appends the keystrokes that type text on a US keyboard. A line feed is typed as Enter.
+func (s *Simulator) QueueKeys(text string) {
+	for i := range len(text) {
+		c := text[i]
+		if c == '\n' {
+			c = '\r'
+		}
+		s.QueueKey(scanCodes[c], c)
+	}
+}
@@ -0,0 +1,145 @@
+package simulator
+
+import (
+	"github.com/8086-simulator/part1/internal/instruction"
+)
+
+// doLogical executes AND, OR, XOR and TEST. CF and OF are cleared, PF, ZF and SF follow the result
+// and AF is undefined, so it is left unchanged.
+func (s *Simulator) doLogical(ins *instruction.Instruction) error {
+	dest, source, err := s.operands(ins)
+	if err != nil {
+		return err
+	}
+	destVal := s.readOperand(dest, ins.WBit)
+	sourceVal := s.readOperand(source, ins.WBit)
+
+	var result uint16
+	switch ins.Op {
+	case instruction.AND, instruction.TEST:
+		result = destVal & sourceVal
+	case instruction.OR:
+		result = destVal | sourceVal
+	case instruction.XOR:
+		result = destVal ^ sourceVal
+	}
+	s.flags["C"] = false
+	s.flags["O"] = false
+	s.setResultFlags(result, ins.WBit)
+	if ins.Op != instruction.TEST {
+		s.writeOperand(dest, ins.WBit, result)
+	}
+	return nil
+}
+
+// doNot executes NOT, which does not affect any flags.
+func (s *Simulator) doNot(ins *instruction.Instruction) {
+	dest := s.destOperand(ins)
+	s.writeOperand(dest, ins.WBit, truncate(^s.readOperand(dest, ins.WBit), ins.WBit))
+}
+
+// doNeg executes NEG as a subtraction from zero, so CF is set for any non-zero operand.
+func (s *Simulator) doNeg(ins *instruction.Instruction) {
+	dest := s.destOperand(ins)
+	value := uint32(s.readOperand(dest, ins.WBit))
+	result := 0 - value
+	s.setArithmeticFlags(true, 0, value, result, ins.WBit)
+	s.writeOperand(dest, ins.WBit, truncate(uint16(result), ins.WBit))
+}
+
+// doIncDec executes INC and DEC, which set the flags of an addition or subtraction of 1 but leave CF alone.
+func (s *Simulator) doIncDec(ins *instruction.Instruction) {
+	dest := s.destOperand(ins)
+	value := uint32(s.readOperand(dest, ins.WBit))
+	result := value + 1
+	if ins.Op == instruction.DEC {
+		result = value - 1
+	}
+	carry := s.flags["C"]
+	s.setArithmeticFlags(ins.Op == instruction.DEC, value, 1, result, ins.WBit)
+	s.flags["C"] = carry
+	s.writeOperand(dest, ins.WBit, truncate(uint16(result), ins.WBit))
+}
+
+// doShift executes the shift and rotate group. The 8086 does not mask the count in CL, so it
+// shifts one bit at a time for the full count. A count of zero leaves the operand and flags alone.
+// OF is only defined for single-bit shifts and is left unchanged for other counts. Rotates only
+// affect CF and OF; shifts also set PF, ZF and SF, while AF is undefined and left unchanged.
+func (s *Simulator) doShift(ins *instruction.Instruction) error {
+	dest, source, err := s.operands(ins)
+	if err != nil {
+		return err
+	}
+	count := s.readOperand(source, false)
+	if count == 0 {
+		return nil
+	}
+
+	signBit := uint16(0x80)
+	if ins.WBit {
+		signBit = 0x8000
+	}
+	value := s.readOperand(dest, ins.WBit)
+	original := value
+	carry := s.flags["C"]
+	for range count {
+		switch ins.Op {
+		case instruction.SHL:
+			carry = value&signBit != 0
+			value <<= 1
+		case instruction.SHR:
+			carry = value&1 != 0
+			value >>= 1
+		case instruction.SAR:
+			carry = value&1 != 0
+			value = value>>1 | value&signBit
+		case instruction.ROL:
+			carry = value&signBit != 0
+			value <<= 1
+			if carry {
+				value |= 1
+			}
+		case instruction.ROR:
+			carry = value&1 != 0
+			value >>= 1
+			if carry {
+				value |= signBit
+			}
+		case instruction.RCL:
+			in := carry
+			carry = value&signBit != 0
+			value <<= 1
+			if in {
+				value |= 1
+			}
+		case instruction.RCR:
+			in := carry
+			carry = value&1 != 0
+			value >>= 1
+			if in {
+				value |= signBit
+			}
+		}
+		value = truncate(value, ins.WBit)
+	}
+
+	s.flags["C"] = carry
+	if count == 1 {
+		switch ins.Op {
+		case instruction.SHL, instruction.ROL, instruction.RCL:
+			s.flags["O"] = (value&signBit != 0) != carry
+		case instruction.SHR:
+			s.flags["O"] = original&signBit != 0
+		case instruction.SAR:
+			s.flags["O"] = false
+		case instruction.ROR, instruction.RCR:
+			s.flags["O"] = (value&signBit != 0) != (value&(signBit>>1) != 0)
+		}
+	}
+	switch ins.Op {
+	case instruction.SHL, instruction.SHR, instruction.SAR:
+		s.setResultFlags(value, ins.WBit)
+	}
+	s.writeOperand(dest, ins.WBit, value)
+	return nil
+}
@@ -0,0 +1,175 @@
+package simulator
+
+import "testing"
+
+func TestSimulatorLogicalShiftRotate(t *testing.T) {
+	tests := []struct {
+		name          string
+		program       []byte
+		register      string
+		expected      uint16
+		expectedFlags string
+	}{
+		{
+			// mov ax, 0xf0f0; and ax, 0x0ff0
+			name:          "and",
+			program:       []byte{0xb8, 0xf0, 0xf0, 0x25, 0xf0, 0x0f},
+			register:      "ax",
+			expected:      0x00f0,
+			expectedFlags: "P",
+		},
+		{
+			// mov bl, 0x80; or bl, 0x01
+			name:          "or",
+			program:       []byte{0xb3, 0x80, 0x80, 0xcb, 0x01},
+			register:      "bx",
+			expected:      0x81,
+			expectedFlags: "PS",
+		},
+		{
+			// mov ax, 1; cmp ax, 2; xor ax, ax
+			name:          "xor clears carry",
+			program:       []byte{0xb8, 0x01, 0x00, 0x3d, 0x02, 0x00, 0x31, 0xc0},
+			register:      "ax",
+			expected:      0,
+			expectedFlags: "PAZ",
+		},
+		{
+			// mov cx, 0x8000; test cx, 0x8000
+			name:          "test does not store",
+			program:       []byte{0xb9, 0x00, 0x80, 0xf7, 0xc1, 0x00, 0x80},
+			register:      "cx",
+			expected:      0x8000,
+			expectedFlags: "PS",
+		},
+		{
+			// mov dx, 0x1234; not dx
+			name:          "not",
+			program:       []byte{0xba, 0x34, 0x12, 0xf7, 0xd2},
+			register:      "dx",
+			expected:      0xedcb,
+			expectedFlags: "",
+		},
+		{
+			// mov ax, 0xffff; add ax, 1; inc ax (ff /0)
+			name:          "inc keeps carry",
+			program:       []byte{0xb8, 0xff, 0xff, 0x05, 0x01, 0x00, 0xff, 0xc0},
+			register:      "ax",
+			expected:      1,
+			expectedFlags: "C",
+		},
+		{
+			// mov cx, 0x8000; dec cx (ff /1)
+			name:          "dec",
+			program:       []byte{0xb9, 0x00, 0x80, 0xff, 0xc9},
+			register:      "cx",
+			expected:      0x7fff,
+			expectedFlags: "PAO",
+		},
+		{
+			// mov al, 5; neg al
+			name:          "neg",
+			program:       []byte{0xb0, 0x05, 0xf6, 0xd8},
+			register:      "ax",
+			expected:      0xfb,
+			expectedFlags: "CAS",
+		},
+		{
+			// mov al, 0x80; neg al
+			name:          "neg most negative",
+			program:       []byte{0xb0, 0x80, 0xf6, 0xd8},
+			register:      "ax",
+			expected:      0x80,
+			expectedFlags: "CSO",
+		},
+		{
+			// mov ax, 0x4001; shl ax, 1
+			name:          "shl by one sets overflow",
+			program:       []byte{0xb8, 0x01, 0x40, 0xd1, 0xe0},
+			register:      "ax",
+			expected:      0x8002,
+			expectedFlags: "SO",
+		},
+		{
+			// mov ax, 0x8001; shr ax, 1
+			name:          "shr by one",
+			program:       []byte{0xb8, 0x01, 0x80, 0xd1, 0xe8},
+			register:      "ax",
+			expected:      0x4000,
+			expectedFlags: "CPO",
+		},
+		{
+			// mov al, 0x81; mov cl, 3; sar al, cl
+			name:          "sar by cl",
+			program:       []byte{0xb0, 0x81, 0xb1, 0x03, 0xd2, 0xf8},
+			register:      "ax",
+			expected:      0xf0,
+			expectedFlags: "PS",
+		},
+		{
+			// mov ax, 1; mov cl, 32; shl ax, cl
+			name:          "count is not masked",
+			program:       []byte{0xb8, 0x01, 0x00, 0xb1, 0x20, 0xd3, 0xe0},
+			register:      "ax",
+			expected:      0,
+			expectedFlags: "PZ",
+		},
+		{
+			// mov ax, 0x1234; mov cl, 0; shl ax, cl
+			name:          "zero count",
+			program:       []byte{0xb8, 0x34, 0x12, 0xb1, 0x00, 0xd3, 0xe0},
+			register:      "ax",
+			expected:      0x1234,
+			expectedFlags: "",
+		},
+		{
+			// mov bl, 0x81; rol bl, 1
+			name:          "rol",
+			program:       []byte{0xb3, 0x81, 0xd0, 0xc3},
+			register:      "bx",
+			expected:      0x03,
+			expectedFlags: "CO",
+		},
+		{
+			// mov bx, 0x0001; mov cl, 4; ror bx, cl
+			name:          "ror by cl",
+			program:       []byte{0xbb, 0x01, 0x00, 0xb1, 0x04, 0xd3, 0xcb},
+			register:      "bx",
+			expected:      0x1000,
+			expectedFlags: "",
+		},
+		{
+			// mov al, 1; cmp al, 2; mov al, 0x80; rcl al, 1
+			name:          "rcl through carry",
+			program:       []byte{0xb0, 0x01, 0x3c, 0x02, 0xb0, 0x80, 0xd0, 0xd0},
+			register:      "ax",
+			expected:      0x01,
+			expectedFlags: "CPASO",
+		},
+		{
+			// mov al, 1; cmp al, 2; mov al, 0x00; rcr al, 1
+			name:          "rcr through carry",
+			program:       []byte{0xb0, 0x01, 0x3c, 0x02, 0xb0, 0x00, 0xd0, 0xd8},
+			register:      "ax",
+			expected:      0x80,
+			expectedFlags: "PASO",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sim := NewSimulator(false)
+			sim.Load(tt.program)
+			if _, err := sim.Run(); err != nil {
+				t.Fatalf("Error running instructions: %v", err)
+			}
+
+			if got := sim.readRegister(tt.register); got != tt.expected {
+				t.Fatalf("Expected %s to be 0x%x but got 0x%x", tt.register, tt.expected, got)
+			}
+			if flags := sim.printFlags(); flags != tt.expectedFlags {
+				t.Fatalf("Expected flags %s but got %s", tt.expectedFlags, flags)
+			}
+		})
+	}
+}
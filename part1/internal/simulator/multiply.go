@@ -0,0 +1,98 @@
+package simulator
+
+import (
+	"github.com/8086-simulator/part1/internal/instruction"
+)
+
+// doMultiply executes MUL and IMUL. The product goes to AX for bytes and DX:AX for words; CF and OF
+// are set when the upper half is significant. SF, ZF, AF and PF are undefined and left untouched.
+func (s *Simulator) doMultiply(ins *instruction.Instruction) {
+	source := s.readOperand(s.destOperand(ins), ins.WBit)
+
+	var upperSignificant bool
+	if ins.WBit {
+		ax := s.readRegister("ax")
+		var product uint32
+		if ins.Op == instruction.IMUL {
+			signed := int32(int16(ax)) * int32(int16(source))
+			product = uint32(signed)
+			upperSignificant = signed != int32(int16(signed))
+		} else {
+			product = uint32(ax) * uint32(source)
+			upperSignificant = product>>16 != 0
+		}
+		s.writeRegister("ax", uint16(product))
+		s.writeRegister("dx", uint16(product>>16))
+	} else {
+		al := s.readRegister("al")
+		var product uint16
+		if ins.Op == instruction.IMUL {
+			signed := int16(int8(al)) * int16(int8(source))
+			product = uint16(signed)
+			upperSignificant = signed != int16(int8(signed))
+		} else {
+			product = al * source
+			upperSignificant = product>>8 != 0
+		}
+		s.writeRegister("ax", product)
+	}
+
+	s.flags["C"] = upperSignificant
+	s.flags["O"] = upperSignificant
+}
+
+// doDivide executes DIV and IDIV, dividing AX by a byte or DX:AX by a word. A zero divisor or a
+// quotient out of range raises a divide error instead; like the 8086, IDIV rejects the most negative
+// quotient (-128 or -32768). All flags are undefined and left untouched.
+func (s *Simulator) doDivide(ins *instruction.Instruction) {
+	divisor := s.readOperand(s.destOperand(ins), ins.WBit)
+	if divisor == 0 {
+		s.interrupt(DivideErrorInterrupt)
+		return
+	}
+
+	if ins.WBit {
+		dividend := uint32(s.readRegister("dx"))<<16 | uint32(s.readRegister("ax"))
+		var quotient, remainder uint32
+		if ins.Op == instruction.IDIV {
+			q := int64(int32(dividend)) / int64(int16(divisor))
+			if q > 0x7FFF || q < -0x7FFF {
+				s.interrupt(DivideErrorInterrupt)
+				return
+			}
+			quotient = uint32(q)
+			remainder = uint32(int64(int32(dividend)) % int64(int16(divisor)))
+		} else {
+			quotient = dividend / uint32(divisor)
+			if quotient > 0xFFFF {
+				s.interrupt(DivideErrorInterrupt)
+				return
+			}
+			remainder = dividend % uint32(divisor)
+		}
+		s.writeRegister("ax", uint16(quotient))
+		s.writeRegister("dx", uint16(remainder))
+		return
+	}
+
+	dividend := s.readRegister("ax")
+	var quotient, remainder uint16
+	if ins.Op == instruction.IDIV {
+		q := int32(int16(dividend)) / int32(int8(divisor))
+		if q > 0x7F || q < -0x7F {
+			s.interrupt(DivideErrorInterrupt)
+			return
+		}
+		quotient = uint16(q)
+		remainder = uint16(int32(int16(dividend)) % int32(int8(divisor)))
+	} else {
+		quotient = dividend / divisor
+		if quotient > 0xFF {
+			s.interrupt(DivideErrorInterrupt)
+			return
+		}
+		remainder = dividend % divisor
+	}
+	s.writeRegister("al", quotient)
+	s.writeRegister("ah", remainder)
+}
@@ -0,0 +1,175 @@
+package simulator
+
+import (
+	"testing"
+
+	"github.com/8086-simulator/part1/internal/memory"
+)
+
+func TestSimulatorMultiplyDivide(t *testing.T) {
+	tests := []struct {
+		name       string
+		program    []byte
+		expectedAX uint16
+		expectedDX uint16
+		expectedCF bool
+	}{
+		{
+			// mov al, 5; mov bl, 3; mul bl
+			name:       "mul byte",
+			program:    []byte{0xb0, 0x05, 0xb3, 0x03, 0xf6, 0xe3},
+			expectedAX: 15,
+		},
+		{
+			// mov al, 200; mov bl, 3; mul bl
+			name:       "mul byte upper half",
+			program:    []byte{0xb0, 0xc8, 0xb3, 0x03, 0xf6, 0xe3},
+			expectedAX: 600,
+			expectedCF: true,
+		},
+		{
+			// mov ax, 0x1234; mov cx, 0x100; mul cx
+			name:       "mul word",
+			program:    []byte{0xb8, 0x34, 0x12, 0xb9, 0x00, 0x01, 0xf7, 0xe1},
+			expectedAX: 0x3400,
+			expectedDX: 0x12,
+			expectedCF: true,
+		},
+		{
+			// mov al, -2; mov bl, 3; imul bl
+			name:       "imul byte",
+			program:    []byte{0xb0, 0xfe, 0xb3, 0x03, 0xf6, 0xeb},
+			expectedAX: 0xfffa,
+		},
+		{
+			// mov al, 100; mov bl, 2; imul bl
+			name:       "imul byte overflow",
+			program:    []byte{0xb0, 0x64, 0xb3, 0x02, 0xf6, 0xeb},
+			expectedAX: 200,
+			expectedCF: true,
+		},
+		{
+			// mov ax, -1000; mov cx, 1000; imul cx
+			name:       "imul word",
+			program:    []byte{0xb8, 0x18, 0xfc, 0xb9, 0xe8, 0x03, 0xf7, 0xe9},
+			expectedAX: 0xbdc0,
+			expectedDX: 0xfff0,
+			expectedCF: true,
+		},
+		{
+			// mov ax, 600; mov bl, 7; div bl
+			name:       "div byte",
+			program:    []byte{0xb8, 0x58, 0x02, 0xb3, 0x07, 0xf6, 0xf3},
+			expectedAX: 0x0555,
+		},
+		{
+			// mov dx, 1; mov ax, 0; mov cx, 3; div cx
+			name:       "div word",
+			program:    []byte{0xba, 0x01, 0x00, 0xb8, 0x00, 0x00, 0xb9, 0x03, 0x00, 0xf7, 0xf1},
+			expectedAX: 0x5555,
+			expectedDX: 1,
+		},
+		{
+			// mov ax, -7; mov bl, 2; idiv bl
+			name:       "idiv byte",
+			program:    []byte{0xb8, 0xf9, 0xff, 0xb3, 0x02, 0xf6, 0xfb},
+			expectedAX: 0xfffd,
+		},
+		{
+			// mov dx, -1; mov ax, -100; mov cx, 7; idiv cx
+			name:       "idiv word",
+			program:    []byte{0xba, 0xff, 0xff, 0xb8, 0x9c, 0xff, 0xb9, 0x07, 0x00, 0xf7, 0xf9},
+			expectedAX: 0xfff2,
+			expectedDX: 0xfffe,
+		},
+		{
+			// mov word [0x200], 10; mov ax, 100; div byte [0x200]
+			name:       "div memory",
+			program:    []byte{0xc7, 0x06, 0x00, 0x02, 0x0a, 0x00, 0xb8, 0x64, 0x00, 0xf6, 0x36, 0x00, 0x02},
+			expectedAX: 10,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sim := NewSimulator(false)
+			sim.Load(tt.program)
+			if _, err := sim.Run(); err != nil {
+				t.Fatalf("Error running instructions: %v", err)
+			}
+
+			if ax := sim.readRegister("ax"); ax != tt.expectedAX {
+				t.Fatalf("Expected ax to be 0x%x but got 0x%x", tt.expectedAX, ax)
+			}
+			if dx := sim.readRegister("dx"); dx != tt.expectedDX {
+				t.Fatalf("Expected dx to be 0x%x but got 0x%x", tt.expectedDX, dx)
+			}
+			if sim.flags["C"] != tt.expectedCF || sim.flags["O"] != tt.expectedCF {
+				t.Fatalf("Expected CF and OF to be %t but got %t and %t", tt.expectedCF, sim.flags["C"], sim.flags["O"])
+			}
+		})
+	}
+}
+
+func TestSimulatorDivideError(t *testing.T) {
+	tests := []struct {
+		name   string
+		divide []byte
+	}{
+		{
+			// mov ax, 10; mov bl, 0; div bl
+			name:   "divide by zero",
+			divide: []byte{0xb8, 0x0a, 0x00, 0xb3, 0x00, 0xf6, 0xf3},
+		},
+		{
+			// mov ax, 0x1000; mov bl, 2; div bl
+			name:   "quotient overflow",
+			divide: []byte{0xb8, 0x00, 0x10, 0xb3, 0x02, 0xf6, 0xf3},
+		},
+		{
+			// mov ax, -256; mov bl, 2; idiv bl
+			name:   "idiv most negative quotient",
+			divide: []byte{0xb8, 0x00, 0xff, 0xb3, 0x02, 0xf6, 0xfb},
+		},
+		{
+			// mov dx, 1; mov ax, 0; mov cx, 1; div cx
+			name:   "word quotient overflow",
+			divide: []byte{0xba, 0x01, 0x00, 0xb8, 0x00, 0x00, 0xb9, 0x01, 0x00, 0xf7, 0xf1},
+		},
+		{
+			// mov al, 5; aam 0
+			name:   "aam zero base",
+			divide: []byte{0xb0, 0x05, 0xd4, 0x00},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sim := NewSimulator(false)
+			sim.writeRegister("cs", 0x100)
+			sim.Load(interruptProgram(0, tt.divide, []byte{
+				0xb9, 0x55, 0x00, // mov cx, 0x55
+				0xf4, // hlt
+			}))
+			if _, err := sim.Run(); err != nil {
+				t.Fatalf("Error running instructions: %v", err)
+			}
+
+			if cx := sim.readRegister("cx"); cx != 0x55 {
+				t.Fatalf("Expected divide error handler to run but cx is 0x%x", cx)
+			}
+			if sp := sim.readRegister("sp"); sp != 0x800-6 {
+				t.Fatalf("Expected FLAGS, CS and IP to be pushed but sp is 0x%x", sp)
+			}
+			// The 8086 pushes the address of the instruction following the divide.
+			returnIP := sim.Memory.Read16(memory.Address(0, 0x800-6))
+			expectedIP := uint16(15 + len(tt.divide))
+			if returnIP != expectedIP {
+				t.Fatalf("Expected return address 0x%x but got 0x%x", expectedIP, returnIP)
+			}
+			if returnCS := sim.Memory.Read16(memory.Address(0, 0x800-4)); returnCS != 0x100 {
+				t.Fatalf("Expected return segment 0x100 but got 0x%x", returnCS)
+			}
+		})
+	}
+}
@@ -0,0 +1,148 @@
+package simulator
+
+import (
+	"fmt"
+
+	"github.com/8086-simulator/part1/internal/bits"
+	"github.com/8086-simulator/part1/internal/instruction"
+)
+
+type operandKind int
+
+const (
+	operandRegister operandKind = iota
+	operandMemory
+	operandImmediate
+)
+
+// operand is a resolved instruction operand: a register, a segment:offset memory location or an immediate value.
+type operand struct {
+	kind      operandKind
+	register  string
+	segment   string
+	offset    uint16
+	immediate uint16
+}
+
+// operands resolves the destination and source of a two-operand instruction.
+func (s *Simulator) operands(ins *instruction.Instruction) (operand, operand, error) {
+	dest := s.destOperand(ins)
+	switch ins.OperandType {
+	case instruction.OpTypeRegMemToFromReg, instruction.OpTypeMemToFromAcc:
+		if ins.SourceAddr != "" {
+			return dest, s.memoryOperand(ins), nil
+		}
+		return dest, operand{kind: operandRegister, register: ins.SourceRegister}, nil
+	case instruction.OpTypeImmToReg, instruction.OpTypeImmToAcc:
+		return dest, operand{kind: operandImmediate, immediate: immediateValue(ins)}, nil
+	case instruction.OpTypeRegMem:
+		// single-operand groups that carry a second operand: shifts by CL or 1 and TEST with an immediate
+		if ins.SourceRegister != "" {
+			return dest, operand{kind: operandRegister, register: ins.SourceRegister}, nil
+		}
+		if ins.Immediate != nil {
+			return dest, operand{kind: operandImmediate, immediate: immediateValue(ins)}, nil
+		}
+		return operand{}, operand{}, fmt.Errorf("instruction has no source operand: %s", ins.Text)
+	default:
+		return operand{}, operand{}, fmt.Errorf("unsupported operand type: %d", ins.OperandType)
+	}
+}
+
+func (s *Simulator) destOperand(ins *instruction.Instruction) operand {
+	if ins.DestAddr != "" {
+		return s.memoryOperand(ins)
+	}
+	return operand{kind: operandRegister, register: ins.DestRegister}
+}
+
+// memoryOperand computes the effective address encoded by the instruction's mod and r/m fields.
+func (s *Simulator) memoryOperand(ins *instruction.Instruction) operand {
+	var disp uint16
+	if raw := memoryDisplacement(ins); len(raw) == 2 {
+		disp = bits.ToUnsigned16(raw[0], raw[1])
+	} else if len(raw) == 1 {
+		disp = uint16(bits.ToSigned8(raw[0]))
+	}
+
+	segment := "ds"
+	var offset uint16
+	switch ins.RM {
+	case 0b000:
+		offset = s.readRegister("bx") + s.readRegister("si")
+	case 0b001:
+		offset = s.readRegister("bx") + s.readRegister("di")
+	case 0b010:
+		offset = s.readRegister("bp") + s.readRegister("si")
+		segment = "ss"
+	case 0b011:
+		offset = s.readRegister("bp") + s.readRegister("di")
+		segment = "ss"
+	case 0b100:
+		offset = s.readRegister("si")
+	case 0b101:
+		offset = s.readRegister("di")
+	case 0b110:
+		// mod 00 with r/m 110 is a direct address rather than [bp]
+		if ins.Mod != 0b00 {
+			offset = s.readRegister("bp")
+			segment = "ss"
+		}
+	case 0b111:
+		offset = s.readRegister("bx")
+	}
+
+	if ins.SegmentOverride != "" {
+		segment = ins.SegmentOverride
+	}
+	return operand{kind: operandMemory, segment: segment, offset: offset + disp}
+}
+
+// memoryDisplacement returns the displacement bytes of the instruction's memory operand, if any.
+func memoryDisplacement(ins *instruction.Instruction) []byte {
+	if ins.DestDisplacement != nil {
+		return ins.DestDisplacement
+	}
+	return ins.SourceDisplacement
+}
+
+// immediateValue returns the instruction's immediate, sign-extending 8-bit data used with 16-bit operands.
+func immediateValue(ins *instruction.Instruction) uint16 {
+	raw := ins.Immediate.Raw
+	if len(raw) == 2 {
+		return bits.ToUnsigned16(raw[0], raw[1])
+	}
+	if ins.WBit && ins.SBit {
+		return uint16(bits.ToSigned8(raw[0]))
+	}
+	return bits.ToUnsigned8(raw[0])
+}
+
+func (s *Simulator) readOperand(op operand, wide bool) uint16 {
+	switch op.kind {
+	case operandRegister:
+		return s.readRegister(op.register)
+	case operandMemory:
+		return s.readMemory(op.segment, op.offset, wide)
+	default:
+		return truncate(op.immediate, wide)
+	}
+}
+
+func (s *Simulator) writeOperand(op operand, wide bool, value uint16) {
+	switch op.kind {
+	case operandRegister:
+		s.writeRegister(op.register, value)
+	case operandMemory:
+		s.writeMemory(op.segment, op.offset, wide, value)
+	}
+}
+
+// readMemory reads a byte or a little-endian word relative to a segment register.
+func (s *Simulator) readMemory(segment string, offset uint16, wide bool) uint16 {
+	return s.busRead(s.readRegister(segment), offset, wide)
+}
+
+func (s *Simulator) writeMemory(segment string, offset uint16, wide bool, value uint16) {
+	s.busWrite(s.readRegister(segment), offset, wide, value)
+}
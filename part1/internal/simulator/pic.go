@@ -0,0 +1,146 @@
+package simulator
+
+const (
+	picCommandPort uint16 = 0x20
+	picDataPort    uint16 = 0x21
+	// TimerIRQ is the interrupt request line the PIT's channel 0 is wired to.
+	TimerIRQ = 0
+)
+
+// pic emulates a single 8259A programmable interrupt controller in 8086 mode. Requests are
+// edge-triggered and IRQ0 has the highest priority.
+type pic struct {
+	// irr, isr and imr are the request, in-service and mask registers; bit n is IRQ n.
+	irr        byte
+	isr        byte
+	imr        byte
+	vectorBase byte
+	// initStep is the next initialization command word expected on the data port, 0 when not initializing.
+	initStep int
+	needICW3 bool
+	needICW4 bool
+	readISR  bool
+}
+
+// newPIC returns a controller set up the way the PC BIOS leaves it: IRQ0-7 on vectors 8-15, nothing masked.
+func newPIC() *pic {
+	return &pic{vectorBase: 0x08}
+}
+
+func (p *pic) In(port uint16) byte {
+	if port == picDataPort {
+		return p.imr
+	}
+	if p.readISR {
+		return p.isr
+	}
+	return p.irr
+}
+
+func (p *pic) Out(port uint16, value byte) {
+	if port == picDataPort {
+		p.writeData(value)
+		return
+	}
+	switch {
+	case value&0x10 != 0: // ICW1
+		p.irr, p.isr, p.imr = 0, 0, 0
+		p.readISR = false
+		p.needICW3 = value&0x02 == 0
+		p.needICW4 = value&0x01 != 0
+		p.initStep = 2
+	case value&0x18 == 0x00: // OCW2
+		switch value & 0xE0 {
+		case 0x20: // non-specific EOI
+			p.endOfInterrupt(p.highestInService())
+		case 0x60: // specific EOI
+			p.endOfInterrupt(int(value & 0x07))
+		}
+	case value&0x18 == 0x08: // OCW3
+		if value&0x02 != 0 {
+			p.readISR = value&0x01 != 0
+		}
+	}
+}
+
+func (p *pic) writeData(value byte) {
+	switch p.initStep {
+	case 2:
+		p.vectorBase = value & 0xF8
+		p.initStep = p.nextInitStep(3)
+	case 3:
+		// ICW3 only matters for cascaded controllers
+		p.initStep = p.nextInitStep(4)
+	case 4:
+		// ICW4 selects 8086 mode and automatic EOI, neither of which changes this model
+		p.initStep = 0
+	default:
+		p.imr = value
+	}
+}
+
+// nextInitStep skips the optional ICW3 and ICW4 when ICW1 said they would not be sent.
+func (p *pic) nextInitStep(step int) int {
+	if step == 3 && !p.needICW3 {
+		step = 4
+	}
+	if step == 4 && !p.needICW4 {
+		return 0
+	}
+	return step
+}
+
+// raise records a rising edge on an interrupt request line.
+func (p *pic) raise(irq int) {
+	p.irr |= 1 << irq
+}
+
+// pending returns the highest priority unmasked request that is not blocked by an interrupt in service.
+func (p *pic) pending() (int, bool) {
+	for irq := range 8 {
+		bit := byte(1) << irq
+		if p.isr&bit != 0 {
+			return 0, false
+		}
+		if p.irr&bit != 0 && p.imr&bit == 0 {
+			return irq, true
+		}
+	}
+	return 0, false
+}
+
+// accepts reports whether a request on the line would be delivered: it is unmasked and neither it nor
+// a higher priority interrupt is in service.
+func (p *pic) accepts(irq int) bool {
+	if p.imr&(1<<irq) != 0 {
+		return false
+	}
+	for higher := range irq + 1 {
+		if p.isr&(1<<higher) != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// acknowledge moves a request into service and returns the vector the CPU should use for it.
+func (p *pic) acknowledge(irq int) byte {
+	p.irr &^= 1 << irq
+	p.isr |= 1 << irq
+	return p.vectorBase + byte(irq)
+}
+
+func (p *pic) highestInService() int {
+	for irq := range 8 {
+		if p.isr&(1<<irq) != 0 {
+			return irq
+		}
+	}
+	return -1
+}
+
+func (p *pic) endOfInterrupt(irq int) {
+	if irq >= 0 {
+		p.isr &^= 1 << irq
+	}
+}
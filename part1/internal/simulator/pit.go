@@ -0,0 +1,207 @@
+package simulator
+
+const (
+	pitChannel0Port uint16 = 0x40
+	pitChannel1Port uint16 = 0x41
+	pitChannel2Port uint16 = 0x42
+	pitCommandPort  uint16 = 0x43
+	// clocksPerPITTick relates the PIT's 1.193182 MHz input to the 4.77 MHz CPU clock; the PC derives
+	// both from the same 14.31818 MHz crystal.
+	clocksPerPITTick = 4
+)
+
+// pit access modes, from bits 5-4 of the control word.
+const (
+	pitAccessLatch = iota
+	pitAccessLow
+	pitAccessHigh
+	pitAccessLowHigh
+)
+
+// pitChannel is one 16-bit down counter of the 8253.
+type pitChannel struct {
+	mode   byte
+	access byte
+	// reload is the programmed count; 0 counts 65536 ticks.
+	reload uint16
+	// count is the number of ticks left in the current period.
+	count int
+	// counting is set once a full count has been written after the control word.
+	counting bool
+	// fired is set once a one-shot mode has reached terminal count.
+	fired bool
+	// writeHigh and readHigh track which byte comes next in low/high access mode.
+	writeHigh bool
+	readHigh  bool
+	latched   bool
+	latch     uint16
+}
+
+// period returns the programmed count in ticks.
+func (c *pitChannel) period() int {
+	if c.reload == 0 {
+		return 0x10000
+	}
+	return int(c.reload)
+}
+
+// tick advances the counter and returns the number of rising edges on its output. Modes 2 and 3
+// produce one edge per period; modes 0 and 4 produce a single edge at terminal count. The
+// hardware-triggered modes 1 and 5 have no gate input here and never fire.
+func (c *pitChannel) tick(ticks int) int {
+	if !c.counting {
+		return 0
+	}
+	c.count -= ticks
+	switch c.mode {
+	case 2, 3:
+		edges := 0
+		for c.count <= 0 {
+			c.count += c.period()
+			edges++
+		}
+		return edges
+	case 0, 4:
+		for c.count <= 0 {
+			c.count += 0x10000
+			if !c.fired {
+				c.fired = true
+				return 1
+			}
+		}
+	default:
+		for c.count <= 0 {
+			c.count += 0x10000
+		}
+	}
+	return 0
+}
+
+func (c *pitChannel) control(value byte) {
+	access := (value >> 4) & 0x03
+	if access == pitAccessLatch {
+		c.latched = true
+		c.latch = uint16(c.count)
+		return
+	}
+	c.access = access
+	c.mode = (value >> 1) & 0x07
+	if c.mode > 5 {
+		// modes 6 and 7 are aliases of 2 and 3
+		c.mode -= 4
+	}
+	c.counting = false
+	c.writeHigh = false
+	c.readHigh = false
+}
+
+func (c *pitChannel) write(value byte) {
+	switch c.access {
+	case pitAccessLow:
+		c.load(uint16(value))
+	case pitAccessHigh:
+		c.load(uint16(value) << 8)
+	case pitAccessLowHigh:
+		if !c.writeHigh {
+			c.reload = c.reload&0xFF00 | uint16(value)
+			c.writeHigh = true
+			return
+		}
+		c.writeHigh = false
+		c.load(c.reload&0x00FF | uint16(value)<<8)
+	}
+}
+
+func (c *pitChannel) load(reload uint16) {
+	c.reload = reload
+	c.count = c.period()
+	c.counting = true
+	c.fired = false
+}
+
+func (c *pitChannel) read() byte {
+	value := uint16(c.count)
+	if c.latched {
+		value = c.latch
+	}
+	var result byte
+	switch c.access {
+	case pitAccessLow:
+		result = byte(value)
+		c.latched = false
+	case pitAccessHigh:
+		result = byte(value >> 8)
+		c.latched = false
+	default:
+		if !c.readHigh {
+			result = byte(value)
+		} else {
+			result = byte(value >> 8)
+			c.latched = false
+		}
+		c.readHigh = !c.readHigh
+	}
+	return result
+}
+
+// pit emulates an 8253 programmable interval timer. Channel 0 drives IRQ0 on the PIC.
+type pit struct {
+	channels [3]pitChannel
+	// clocks holds CPU clocks not yet converted to whole PIT ticks.
+	clocks int
+	// channel2Changed is called when channel 2 is reprogrammed; it drives the speaker.
+	channel2Changed func()
+}
+
+func (p *pit) In(port uint16) byte {
+	if port == pitCommandPort {
+		return 0xFF
+	}
+	return p.channels[port-pitChannel0Port].read()
+}
+
+func (p *pit) Out(port uint16, value byte) {
+	channel := port - pitChannel0Port
+	if port == pitCommandPort {
+		channel = uint16(value >> 6)
+		if channel < 3 {
+			p.channels[channel].control(value)
+		}
+	} else {
+		p.channels[channel].write(value)
+	}
+	if channel == 2 && p.channel2Changed != nil {
+		p.channel2Changed()
+	}
+}
+
+// advance runs the timer for the given number of CPU clocks and returns the rising edges on channel 0.
+func (p *pit) advance(clocks int) int {
+	p.clocks += clocks
+	ticks := p.clocks / clocksPerPITTick
+	p.clocks %= clocksPerPITTick
+	if ticks == 0 {
+		return 0
+	}
+	p.channels[1].tick(ticks)
+	p.channels[2].tick(ticks)
+	return p.channels[0].tick(ticks)
+}
+
+// willFire reports whether channel 0 will produce another rising edge.
+func (p *pit) willFire() bool {
+	c := &p.channels[0]
+	switch c.mode {
+	case 2, 3:
+		return c.counting
+	case 0, 4:
+		return c.counting && !c.fired
+	default:
+		return false
+	}
+}
+
+// clocksToEdge returns the CPU clocks until channel 0's next rising edge.
+func (p *pit) clocksToEdge() int {
+	return max(1, p.channels[0].count*clocksPerPITTick-p.clocks)
+}
@@ -0,0 +1,68 @@
+package simulator
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/8086-simulator/part1/internal/instruction"
+)
+
+// PortDevice is a peripheral attached to the I/O address space. Word IN and OUT reach devices as two
+// byte accesses, the low byte at the given port and the high byte at the next one.
+type PortDevice interface {
+	In(port uint16) byte
+	Out(port uint16, value byte)
+}
+
+// AttachPortDevice maps each of the given ports to the device, replacing any device already there.
+// Devices stay attached across Init.
+func (s *Simulator) AttachPortDevice(device PortDevice, ports ...uint16) {
+	for _, port := range ports {
+		s.ports[port] = device
+	}
+}
+
+// SetLogger sets where the simulator reports unusual events such as accesses to unattached ports.
+func (s *Simulator) SetLogger(logger *log.Logger) {
+	s.logger = logger
+}
+
+// readPort reads a byte from an I/O port. Nothing drives the bus for an unattached port, so it reads as 0xFF.
+func (s *Simulator) readPort(port uint16) byte {
+	if device, ok := s.ports[port]; ok {
+		return device.In(port)
+	}
+	s.logger.Printf("read from unhandled port 0x%x", port)
+	return 0xFF
+}
+
+func (s *Simulator) writePort(port uint16, value byte) {
+	if device, ok := s.ports[port]; ok {
+		device.Out(port, value)
+		return
+	}
+	s.logger.Printf("write of 0x%x to unhandled port 0x%x", value, port)
+}
+
+// doPortIO executes IN and OUT with the port given as an immediate or in DX.
+func (s *Simulator) doPortIO(ins *instruction.Instruction) {
+	port := s.readRegister("dx")
+	if ins.Immediate != nil {
+		port = uint16(ins.Immediate.Value)
+	}
+	if ins.Op == instruction.IN {
+		value := uint16(s.readPort(port))
+		if ins.WBit {
+			value |= uint16(s.readPort(port+1)) << 8
+		}
+		s.writeRegister(ins.DestRegister, value)
+		s.portTrace += fmt.Sprintf(" in[0x%x]=0x%x", port, value)
+		return
+	}
+	value := s.readRegister(ins.DestRegister)
+	s.writePort(port, byte(value))
+	if ins.WBit {
+		s.writePort(port+1, byte(value>>8))
+	}
+	s.portTrace += fmt.Sprintf(" out[0x%x]=0x%x", port, value)
+}
@@ -0,0 +1,103 @@
+package simulator
+
+import (
+	"bytes"
+	"log"
+	"strings"
+	"testing"
+)
+
+// latchDevice stores the last byte written to each port and returns it on reads.
+type latchDevice struct {
+	values map[uint16]byte
+}
+
+func (d *latchDevice) In(port uint16) byte {
+	return d.values[port]
+}
+
+func (d *latchDevice) Out(port uint16, value byte) {
+	d.values[port] = value
+}
+
+func TestSimulatorPortDevice(t *testing.T) {
+	sim := NewSimulator(false)
+	device := &latchDevice{values: map[uint16]byte{}}
+	sim.AttachPortDevice(device, 0x300, 0x301, 0x80)
+	expectedLogs := []string{
+		"mov dx, 768 ; dx:0x0->0x300",
+		"mov ax, 4660 ; ax:0x0->0x1234",
+		"out dx, ax ; out[0x300]=0x1234",
+		"mov al, 171 ; ax:0x1234->0x12ab",
+		"out 128, al ; out[0x80]=0xab",
+		"in ax, dx ; ax:0x12ab->0x1234 in[0x300]=0x1234",
+		"in al, 128 ; ax:0x1234->0x12ab in[0x80]=0xab",
+	}
+
+	sim.Load([]byte{
+		0xba, 0x00, 0x03, // mov dx, 0x300
+		0xb8, 0x34, 0x12, // mov ax, 0x1234
+		0xef,       // out dx, ax
+		0xb0, 0xab, // mov al, 0xab
+		0xe6, 0x80, // out 128, al
+		0xed,       // in ax, dx
+		0xe4, 0x80, // in al, 128
+	})
+	results, err := sim.Run()
+	if err != nil {
+		t.Fatalf("Error running instructions: %v", err)
+	}
+
+	if len(results) != len(expectedLogs) {
+		t.Fatalf("Expected %d instructions but got %d", len(expectedLogs), len(results))
+	}
+	for i, result := range results {
+		if result.Text != expectedLogs[i] {
+			t.Fatalf("\nExpected instruction: %s\n                 Got: %s", expectedLogs[i], result.Text)
+		}
+	}
+	if device.values[0x300] != 0x34 || device.values[0x301] != 0x12 {
+		t.Fatalf("Expected the word to be written as two bytes but got %v", device.values)
+	}
+}
+
+func TestSimulatorUnhandledPort(t *testing.T) {
+	sim := NewSimulator(false)
+	var logs bytes.Buffer
+	sim.SetLogger(log.New(&logs, "", 0))
+
+	sim.Load([]byte{
+		0xe5, 0x10, // in ax, 16
+		0xe6, 0x11, // out 17, al
+	})
+	if _, err := sim.Run(); err != nil {
+		t.Fatalf("Error running instructions: %v", err)
+	}
+
+	if ax := sim.readRegister("ax"); ax != 0xffff {
+		t.Fatalf("Expected unhandled ports to read as 0xff but got 0x%x", ax)
+	}
+	for _, expected := range []string{
+		"read from unhandled port 0x10",
+		"read from unhandled port 0x11",
+		"write of 0xff to unhandled port 0x11",
+	} {
+		if !strings.Contains(logs.String(), expected) {
+			t.Fatalf("Expected log to contain %q but got %q", expected, logs.String())
+		}
+	}
+}
+
+func TestSimulatorPortDeviceSurvivesInit(t *testing.T) {
+	sim := NewSimulator(false)
+	device := &latchDevice{values: map[uint16]byte{0x300: 0x5a}}
+	sim.AttachPortDevice(device, 0x300)
+	sim.Init()
+
+	if value := sim.readPort(0x300); value != 0x5a {
+		t.Fatalf("Expected the attached device to read 0x5a after Init but got 0x%x", value)
+	}
+	if sim.ports[picCommandPort] != sim.pic {
+		t.Fatalf("Expected Init to attach the new PIC")
+	}
+}
@@ -0,0 +1,88 @@
+package simulator
+
+// TimingModel selects how clock estimates are computed.
+type TimingModel int
+
+const (
+	// TimingTable sums the per-instruction costs from the 8086 manual, which assume the next
+	// instruction is already waiting in the prefetch queue.
+	TimingTable TimingModel = iota
+	// TimingPrefetch also models the bus interface unit (BIU) filling the prefetch queue while the
+	// execution unit (EU) runs. Code fetches compete with data transfers for the bus, the EU waits
+	// whenever the queue does not yet hold the whole next instruction, and jumps flush the queue.
+	TimingPrefetch
+)
+
+const (
+	// busCycleClocks is the length of one bus cycle, whether it fetches code or transfers data.
+	busCycleClocks = 4
+	// emptyQueue marks a queue that holds no bytes from any address.
+	emptyQueue = ^uint32(0)
+)
+
+// SetTimingModel selects how clock estimates are computed; the table model is the default.
+func (s *Simulator) SetTimingModel(model TimingModel) {
+	s.timingModel = model
+}
+
+// prefetchQueue tracks the bytes the BIU has fetched ahead of the EU.
+type prefetchQueue struct {
+	// next is the physical address of the first queued byte, the start of the next instruction.
+	next uint32
+	// queued is the number of bytes fetched from next onwards.
+	queued int
+}
+
+func (q *prefetchQueue) reset() {
+	q.next = emptyQueue
+	q.queued = 0
+}
+
+// queueSize returns the capacity of the prefetch queue: 6 bytes on the 8086 and 4 on the 8088.
+func (s *Simulator) queueSize() int {
+	if s.cpuModel == CPU8088 {
+		return 4
+	}
+	return 6
+}
+
+// fetchWidth returns how many code bytes one bus cycle brings in. The 8086 fetches aligned words,
+// so a fetch from an odd address only brings a single byte.
+func (s *Simulator) fetchWidth(addr uint32) int {
+	if s.cpuModel == CPU8088 || addr%2 == 1 {
+		return 1
+	}
+	return 2
+}
+
+// waitForInstruction returns the clocks the EU waits for the BIU to fetch an instruction of the given
+// size starting at start, and consumes it from the queue. A queue holding another address is flushed.
+func (s *Simulator) waitForInstruction(start uint32, size int) int {
+	q := &s.queue
+	if q.next != start {
+		q.next = start
+		q.queued = 0
+	}
+
+	wait := 0
+	for q.queued < size {
+		q.queued += s.fetchWidth(q.next + uint32(q.queued))
+		wait += busCycleClocks
+	}
+	q.queued -= size
+	q.next += uint32(size)
+	return wait
+}
+
+// prefetchDuring lets the BIU fill the queue during the bus cycles an instruction leaves idle.
+func (s *Simulator) prefetchDuring(clocks int, transfers int) {
+	q := &s.queue
+	idleCycles := (clocks - transfers*busCycleClocks) / busCycleClocks
+	for range idleCycles {
+		width := s.fetchWidth(q.next + uint32(q.queued))
+		if q.queued+width > s.queueSize() {
+			return
+		}
+		q.queued += width
+	}
+}
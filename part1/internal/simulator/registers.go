@@ -0,0 +1,64 @@
+package simulator
+
+import (
+	"fmt"
+
+	"github.com/8086-simulator/part1/internal/bits"
+)
+
+// subRegister locates an 8-bit register inside its 16-bit parent.
+type subRegister struct {
+	parent string
+	index  int
+}
+
+var subRegisters = map[string]subRegister{
+	"al": {"ax", 0},
+	"ah": {"ax", 1},
+	"cl": {"cx", 0},
+	"ch": {"cx", 1},
+	"dl": {"dx", 0},
+	"dh": {"dx", 1},
+	"bl": {"bx", 0},
+	"bh": {"bx", 1},
+}
+
+// readRegister returns the value of a 16-bit register or of an 8-bit half such as "al".
+func (s *Simulator) readRegister(name string) uint16 {
+	if sub, ok := subRegisters[name]; ok {
+		return bits.ToUnsigned8(s.Registers[sub.parent][sub.index])
+	}
+	reg := s.Registers[name]
+	return bits.ToUnsigned16(reg[0], reg[1])
+}
+
+// writeRegister stores a value into a 16-bit register or an 8-bit half such as "al".
+func (s *Simulator) writeRegister(name string, value uint16) {
+	if sub, ok := subRegisters[name]; ok {
+		reg := make([]byte, 2)
+		copy(reg, s.Registers[sub.parent])
+		reg[sub.index] = byte(value)
+		s.Registers[sub.parent] = reg
+		return
+	}
+	s.Registers[name] = bits.Uint16ToBytes(value)
+}
+
+func (s *Simulator) snapshotRegisters() map[string]uint16 {
+	snapshot := make(map[string]uint16, len(s.registerOrder))
+	for _, reg := range s.registerOrder {
+		snapshot[reg] = s.readRegister(reg)
+	}
+	return snapshot
+}
+
+// printRegisterChanges lists every register that differs from the snapshot, in register order.
+func (s *Simulator) printRegisterChanges(snapshot map[string]uint16) string {
+	changes := ""
+	for _, reg := range s.registerOrder {
+		if newVal := s.readRegister(reg); newVal != snapshot[reg] {
+			changes += fmt.Sprintf(" %s:0x%x->0x%x", reg, snapshot[reg], newVal)
+		}
+	}
+	return changes
+}
@@ -0,0 +1,34 @@
+package simulator
+
+import (
+	"fmt"
+
+	"github.com/8086-simulator/part1/internal/memory"
+)
+
+const (
+	// resetSegment:resetOffset is where the 8086 starts after a reset, 16 bytes below the top of memory.
+	resetSegment uint16 = 0xFFFF
+	resetOffset  uint16 = 0x0000
+	// maxROMSize is the C0000-FFFFF area the PC reserves for ROM.
+	maxROMSize = 0x40000
+	// minROMSize covers the reset vector.
+	minROMSize = 16
+)
+
+// LoadROM maps a BIOS ROM image read-only at the top of the address space and resets the CPU, so
+// execution starts at FFFF:0000 like on real hardware. No built-in handlers are installed; the ROM
+// sets up the interrupt vectors itself. Run stops on HLT.
+func (s *Simulator) LoadROM(rom []byte) error {
+	if len(rom) < minROMSize || len(rom) > maxROMSize {
+		return fmt.Errorf("ROM image is %d bytes, it must be between %d and %d", len(rom), minROMSize, maxROMSize)
+	}
+	s.Memory.LoadROM(memory.Size-uint32(len(rom)), rom)
+	for _, reg := range s.registerOrder {
+		s.writeRegister(reg, 0)
+	}
+	s.writeRegister("cs", resetSegment)
+	s.writeRegister("ip", resetOffset)
+	s.runUntilTerminated()
+	return nil
+}
@@ -0,0 +1,48 @@
+package simulator
+
+import "testing"
+
+func TestSimulatorLoadROM(t *testing.T) {
+	rom := make([]byte, 0x2000)
+	copy(rom, []byte{
+		0xb8, 0x00, 0xf0, // mov ax, 0xf000
+		0x8e, 0xd8, // mov ds, ax
+		0xc6, 0x06, 0x00, 0xe1, 0x55, // mov byte [0xe100], 0x55
+		0x8a, 0x1e, 0x00, 0xe1, // mov bl, [0xe100]
+		0x31, 0xc0, // xor ax, ax
+		0x8e, 0xd8, // mov ds, ax
+		0xc7, 0x06, 0x20, 0x00, 0x34, 0x12, // mov word [0x20], 0x1234
+		0xf4, // hlt
+	})
+	rom[0x100] = 0xaa
+	// the reset vector at FFFF:0000 jumps to the start of the ROM
+	copy(rom[len(rom)-16:], []byte{0xea, 0x00, 0xe0, 0x00, 0xf0}) // jmp 0xf000:0xe000
+
+	sim := NewSimulator(false)
+	if err := sim.LoadROM(rom); err != nil {
+		t.Fatalf("Error loading ROM: %v", err)
+	}
+	if _, err := sim.Run(); err != nil {
+		t.Fatalf("Error running instructions: %v", err)
+	}
+
+	if !sim.Halted() {
+		t.Fatalf("Expected the ROM to halt")
+	}
+	if bl := sim.readRegister("bl"); bl != 0xaa {
+		t.Fatalf("Expected the write to ROM to be ignored, bl is 0x%x", bl)
+	}
+	if vector := sim.Memory.Read16(0x20); vector != 0x1234 {
+		t.Fatalf("Expected the ROM to set up the IVT but got 0x%x", vector)
+	}
+}
+
+func TestSimulatorLoadROMSize(t *testing.T) {
+	sim := NewSimulator(false)
+	if err := sim.LoadROM(make([]byte, 8)); err == nil {
+		t.Fatalf("Expected an error for a ROM without a reset vector")
+	}
+	if err := sim.LoadROM(make([]byte, maxROMSize+1)); err == nil {
+		t.Fatalf("Expected an error for an oversized ROM")
+	}
+}
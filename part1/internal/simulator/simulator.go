@@ -1,38 +1,96 @@
 package simulator
 
 import (
-	"encoding/binary"
+	"bufio"
 	"fmt"
+	"io"
+	"log"
+	"os"
 
 	"github.com/8086-simulator/part1/internal/bits"
+	"github.com/8086-simulator/part1/internal/decoder"
 	"github.com/8086-simulator/part1/internal/instruction"
+	"github.com/8086-simulator/part1/internal/memory"
 )
 
+// fetchWindow is how many bytes are read at CS:IP before decoding; it covers the longest 8086 instruction.
+const fetchWindow = 16
+
 type Result struct {
 	Text string
+	// Clocks is the instruction's estimated cost.
+	Clocks int
+	// Bus counts the data transfers made by the instruction.
+	Bus BusStats
 }
 
 type Simulator struct {
 	Registers       map[string][]byte
-	Memory          map[uint16][]byte
+	Memory          *memory.Memory
 	registerOrder   []string
 	flags           map[string]bool
 	flagOrder       []string
 	printIPRegister bool
+	decoder         *decoder.Decoder
+	programStart    uint32
+	programEnd      uint32
+	halted          bool
+	estimateClocks  bool
+	cpuModel        CPUModel
+	totalClocks     int
+	branchTaken     bool
+	repetitions     int
+	bus             BusStats
+	instructionBus  BusStats
+	timingModel     TimingModel
+	queue           prefetchQueue
+	ports           map[uint16]PortDevice
+	portTrace       string
+	logger          *log.Logger
+	pic             *pic
+	pit             *pit
+	speaker         *speaker
+	uart            *uart
+	// interruptShadow holds off hardware interrupts for one instruction after STI, MOV SS or POP SS.
+	interruptShadow bool
+	handlers        map[byte]interruptHandler
+	// handlersInstalled is set once the vectors point at the built-in handler stubs.
+	handlersInstalled bool
+	// handlerDone marks that the handler for the stub at CS:IP already ran and its IRET is next.
+	handlerDone bool
+	terminated  bool
+	exitCode    byte
+	stdin       *bufio.Reader
+	stdout      io.Writer
+	// drive is the host directory mounted as C:, and files are the DOS handles open on it.
+	drive *os.Root
+	files map[uint16]*os.File
+	// keys is the scripted keyboard queue read by INT 16h, each entry a scan code and ASCII pair.
+	keys []uint16
+	// tickOffset moves the INT 1Ah tick count away from the one derived from the clocks.
+	tickOffset int
+	floppy     *floppy
+	// breakpoints are called before the instruction at their physical address executes.
+	breakpoints map[uint32]func() error
 }
 
 func NewSimulator(printIPRegister bool) *Simulator {
 	s := &Simulator{
 		Registers:       make(map[string][]byte),
-		Memory:          make(map[uint16][]byte),
 		printIPRegister: printIPRegister,
+		decoder:         decoder.NewDecoder(),
+		logger:          log.Default(),
+		stdin:           bufio.NewReader(os.Stdin),
+		stdout:          os.Stdout,
+		ports:           make(map[uint16]PortDevice),
+		breakpoints:     make(map[uint32]func() error),
 	}
 	s.Init()
 	return s
 }
 
 func (s *Simulator) Init() {
-	s.registerOrder = []string{"ax", "bx", "cx", "dx", "sp", "bp", "si", "di"}
+	s.registerOrder = []string{"ax", "bx", "cx", "dx", "sp", "bp", "si", "di", "es", "cs", "ss", "ds"}
 	s.Registers = map[string][]byte{
 		"ax": {0, 0},
 		"bx": {0, 0},
@@ -42,13 +100,69 @@ func (s *Simulator) Init() {
 		"bp": {0, 0},
 		"si": {0, 0},
 		"di": {0, 0},
+		"es": {0, 0},
+		"cs": {0, 0},
+		"ss": {0, 0},
+		"ds": {0, 0},
 		"ip": {0, 0},
 	}
 	s.flags = map[string]bool{
+		"C": false,
+		"P": false,
+		"A": false,
 		"Z": false,
 		"S": false,
+		"T": false,
+		"I": false,
+		"D": false,
+		"O": false,
+	}
+	s.flagOrder = []string{"C", "P", "A", "Z", "S", "T", "I", "D", "O"}
+	s.Memory = memory.NewMemory()
+	s.programStart = 0
+	s.programEnd = 0
+	s.halted = false
+	s.totalClocks = 0
+	s.bus = BusStats{}
+	s.queue.reset()
+	// devices attached with AttachPortDevice stay; only the built-in ones are replaced
+	s.pic = newPIC()
+	s.pit = &pit{}
+	s.AttachPortDevice(s.pic, picCommandPort, picDataPort)
+	s.AttachPortDevice(s.pit, pitChannel0Port, pitChannel1Port, pitChannel2Port, pitCommandPort)
+	s.speaker = &speaker{pit: s.pit, clock: &s.totalClocks}
+	s.pit.channel2Changed = s.speaker.record
+	s.AttachPortDevice(s.speaker, speakerPort)
+	// the UART comes out of reset but stays connected to the host streams given to AttachSerial
+	uart := &uart{raise: s.pic.raise}
+	if s.uart != nil {
+		uart.in, uart.out = s.uart.in, s.uart.out
+	}
+	s.uart = uart
+	for port := com1Port; port < com1Port+uartRegisterCount; port++ {
+		s.AttachPortDevice(s.uart, port)
 	}
-	s.flagOrder = []string{"Z", "S"}
+	s.interruptShadow = false
+	s.handlers = nil
+	s.handlersInstalled = false
+	s.handlerDone = false
+	s.terminated = false
+	s.exitCode = 0
+	s.unmountDrive()
+	s.tickOffset = 0
+	s.floppy = nil
+}
+
+// Load copies the program into memory at CS:IP. Run stops once execution leaves the loaded bytes.
+func (s *Simulator) Load(program []byte) {
+	s.programStart = memory.Address(s.readRegister("cs"), s.readRegister("ip"))
+	s.programEnd = s.programStart + uint32(len(program))
+	s.Memory.Load(s.programStart, program)
+}
+
+// Halted reports whether the simulator stopped on a HLT instruction.
+func (s *Simulator) Halted() bool {
+	return s.halted
 }
 
 func (s *Simulator) printImmediateValue(rawData []byte) uint16 {
@@ -60,322 +174,338 @@ func (s *Simulator) printImmediateValue(rawData []byte) uint16 {
 	return bits.ToUnsigned8(rawData[0])
 }
 
-func (s *Simulator) updateIPRegister(ipRegVal int) string {
-	if !s.printIPRegister {
-		return ""
+// Run executes instructions from CS:IP until the program terminates through DOS, until IP leaves the
+// loaded program or until a HLT that no interrupt can end.
+func (s *Simulator) Run() ([]*Result, error) {
+	results := []*Result{}
+	for !s.terminated && (s.halted && s.canWake() || !s.halted && s.insideProgram()) {
+		result, err := s.Step()
+		if err != nil {
+			return results, err
+		}
+		results = append(results, result)
 	}
-	ipPrevVal := make([]byte, len(s.Registers["ip"]))
-	copy(ipPrevVal, s.Registers["ip"])
-	binary.LittleEndian.PutUint16(s.Registers["ip"], uint16(ipRegVal))
 
-	ipPrevValUint16 := binary.LittleEndian.Uint16(ipPrevVal)
-	return fmt.Sprintf(" ip:0x%x->0x%x", ipPrevValUint16, ipRegVal)
+	return results, nil
 }
 
-func (s *Simulator) Run(instructions []*instruction.Instruction) ([]*Result, error) {
-	ipIdxMap := make(map[int]int)
-	for i, ins := range instructions {
-		ipIdxMap[ins.IPRegister] = i
+// Step fetches, decodes and executes the instruction at CS:IP. When IF is set and the PIC has an
+// unmasked request, the step services that hardware interrupt instead. Reaching a handler stub runs
+// the built-in handler as a step of its own. After a HLT the step waits for the next hardware interrupt.
+func (s *Simulator) Step() (*Result, error) {
+	if s.halted {
+		return s.wake()
 	}
 
-	results := []*Result{}
-	i := 0
-	for i < len(instructions) {
-		ins := instructions[i]
-		switch ins.Op {
-		case instruction.MOV:
-			switch ins.OperandType {
-			case instruction.OpTypeImmToReg:
-				if ins.DestAddr == "" {
-					destPrevVal := s.Registers[ins.DestRegister]
-					s.Registers[ins.DestRegister] = ins.Immediate.Raw
-					ipLog := s.updateIPRegister(ins.IPRegister)
-					results = append(
-						results,
-						&Result{
-							Text: fmt.Sprintf(
-								"%s %s, %d ; %s:0x%x->0x%x%s",
-								ins.Op,
-								ins.DestRegister,
-								s.printImmediateValue(ins.Immediate.Raw),
-								ins.DestRegister,
-								s.printImmediateValue(destPrevVal),
-								s.printImmediateValue(ins.Immediate.Raw),
-								ipLog,
-							),
-						},
-					)
-				} else {
-					disp := ""
-					var ipLog string
-					if len(ins.DestDisplacement) == 2 {
-						addr := bits.ToUnsigned16(ins.DestDisplacement[0], ins.DestDisplacement[1])
-						// Add base register if present
-						if ins.DestAddr != "" && ins.DestAddr != "bp" {
-							baseReg := s.Registers[ins.DestAddr]
-							baseAddr := bits.ToUnsigned16(baseReg[0], baseReg[1])
-							addr += baseAddr
-						}
-						s.Memory[addr] = ins.Immediate.Raw
-						disp = fmt.Sprintf("%d", bits.ToUnsigned16(ins.DestDisplacement[0], ins.DestDisplacement[1]))
-					} else {
-						addr := bits.ToUnsigned8(ins.DestDisplacement[0])
-						// Add base register if present
-						if ins.DestAddr != "" && ins.DestAddr != "bp" {
-							baseReg := s.Registers[ins.DestAddr]
-							baseAddr := bits.ToUnsigned16(baseReg[0], baseReg[1])
-							addr += uint16(baseAddr)
-						}
-						s.Memory[addr] = ins.Immediate.Raw
-						disp = fmt.Sprintf("%d", bits.ToUnsigned8(ins.DestDisplacement[0]))
-					}
-					ipLog = s.updateIPRegister(ins.IPRegister)
-					results = append(
-						results,
-						&Result{
-							Text: fmt.Sprintf(
-								"%s word [%s+%s], %d ;%s",
-								ins.Op,
-								ins.DestAddr,
-								disp,
-								s.printImmediateValue(ins.Immediate.Raw),
-								ipLog,
-							),
-						},
-					)
-				}
-			case instruction.OpTypeRegMemToFromReg:
-				if len(ins.SourceDisplacement) > 0 {
-					// Memory to register
-					var sourceVal []byte
-					var addr uint16
-					if len(ins.SourceDisplacement) >= 2 {
-						addr = bits.ToUnsigned16(ins.SourceDisplacement[0], ins.SourceDisplacement[1])
-						sourceVal = s.Memory[addr]
-					} else if len(ins.SourceDisplacement) == 1 {
-						addr = bits.ToUnsigned8(ins.SourceDisplacement[0])
-						sourceVal = s.Memory[addr]
-					}
-					if sourceVal == nil {
-						sourceVal = []byte{0, 0}
-					}
-					destPrevVal := s.Registers[ins.DestRegister]
-					s.Registers[ins.DestRegister] = sourceVal
-					ipLog := s.updateIPRegister(ins.IPRegister)
-					results = append(
-						results,
-						&Result{Text: fmt.Sprintf(
-							"%s ; %s:0x%x->0x%x%s",
-							ins.Text,
-							ins.DestRegister,
-							s.printImmediateValue(destPrevVal),
-							s.printImmediateValue(sourceVal),
-							ipLog,
-						)},
-					)
-				} else if ins.SourceRegister != "" {
-					// Register to register
-					destPrevVal := s.Registers[ins.DestRegister]
-					sourceVal := s.Registers[ins.SourceRegister]
-					s.Registers[ins.DestRegister] = sourceVal
-					ipLog := s.updateIPRegister(ins.IPRegister)
-					results = append(
-						results,
-						&Result{Text: fmt.Sprintf("%s ; %s:0x%x->0x%x%s", ins.Text, ins.DestRegister, s.printImmediateValue(destPrevVal), s.printImmediateValue(sourceVal), ipLog)},
-					)
-				} else if len(ins.DestDisplacement) == 0 {
-					destPrevVal := s.Registers[ins.DestRegister]
-					s.Registers[ins.DestRegister] = ins.Immediate.Raw
-					ipLog := s.updateIPRegister(ins.IPRegister)
-					results = append(
-						results,
-						&Result{
-							Text: fmt.Sprintf(
-								"%s %s, %d ; %s:0x%x->0x%x%s",
-								ins.Op,
-								ins.DestRegister,
-								s.printImmediateValue(ins.Immediate.Raw),
-								ins.DestRegister,
-								s.printImmediateValue(destPrevVal),
-								s.printImmediateValue(ins.Immediate.Raw),
-								ipLog,
-							),
-						},
-					)
-				} else {
-					disp := ""
-					var ipLog string
-					if ins.Immediate != nil && len(ins.DestDisplacement) >= 2 {
-						s.Memory[bits.ToUnsigned16(ins.DestDisplacement[0], ins.DestDisplacement[1])] = ins.Immediate.Raw
-						disp = fmt.Sprintf("%d", bits.ToUnsigned16(ins.DestDisplacement[0], ins.DestDisplacement[1]))
-					} else if ins.Immediate != nil && len(ins.DestDisplacement) == 1 {
-						s.Memory[bits.ToUnsigned8(ins.DestDisplacement[0])] = ins.Immediate.Raw
-						disp = fmt.Sprintf("%d", bits.ToUnsigned8(ins.DestDisplacement[0]))
-					}
-					ipLog = s.updateIPRegister(ins.IPRegister)
-					immediateValue := 0
-					if ins.Immediate != nil {
-						immediateValue = int(s.printImmediateValue(ins.Immediate.Raw))
-					}
-					results = append(
-						results,
-						&Result{
-							Text: fmt.Sprintf(
-								"%s word [%s+%s], %d ;%s",
-								ins.Op,
-								ins.DestAddr,
-								disp,
-								immediateValue,
-								ipLog,
-							),
-						},
-					)
-				}
-			default:
-				return nil, fmt.Errorf("unsupported operand type: %d", ins.OperandType)
-			}
-		case instruction.ADD, instruction.SUB, instruction.CMP:
-			switch ins.OperandType {
-			case instruction.OpTypeImmToReg:
-				destPrevVal := s.Registers[ins.DestRegister]
-				s.Registers[ins.DestRegister] = s.doArithmeticOp(ins, destPrevVal, ins.Immediate.Raw, ins.DestRegister)
-				ipLog := s.updateIPRegister(ins.IPRegister)
-				flagsNewVal := s.printFlags()
-				if flagsNewVal != "" {
-					results = append(
-						results,
-						&Result{
-							Text: fmt.Sprintf(
-								"%s ; %s:0x%x->0x%x%s flags:->%s",
-								ins.Text,
-								ins.DestRegister,
-								s.printImmediateValue(destPrevVal),
-								s.printImmediateValue(s.Registers[ins.DestRegister]),
-								ipLog,
-								flagsNewVal,
-							),
-						},
-					)
-				} else {
-					results = append(
-						results,
-						&Result{
-							Text: fmt.Sprintf(
-								"%s ; %s:0x%x->0x%x%s",
-								ins.Text,
-								ins.DestRegister,
-								s.printImmediateValue(destPrevVal),
-								s.printImmediateValue(s.Registers[ins.DestRegister]),
-								ipLog,
-							),
-						},
-					)
-				}
-			case instruction.OpTypeRegMemToFromReg:
-				destPrevVal := s.Registers[ins.DestRegister]
-				sourceVal := s.Registers[ins.SourceRegister]
-				flagsPrevVal := s.printFlags()
-				result := s.doArithmeticOp(ins, destPrevVal, sourceVal, ins.DestRegister)
-				ipLog := s.updateIPRegister(ins.IPRegister)
-				flagsNewVal := s.printFlags()
-				if ins.Op == instruction.CMP {
-					// CMP doesn't modify the destination register
-					if flagsPrevVal != flagsNewVal {
-						results = append(
-							results,
-							&Result{Text: fmt.Sprintf("%s ; flags:%s->%s%s", ins.Text, flagsPrevVal, flagsNewVal, ipLog)},
-						)
-					} else {
-						results = append(
-							results,
-							&Result{Text: fmt.Sprintf("%s ; flags:%s->%s%s", ins.Text, flagsPrevVal, flagsNewVal, ipLog)},
-						)
-					}
-				} else {
-					if flagsNewVal != "" {
-						results = append(
-							results,
-							&Result{
-								Text: fmt.Sprintf(
-									"%s ; %s:0x%x->0x%x%s flags:->%s",
-									ins.Text,
-									ins.DestRegister,
-									s.printImmediateValue(destPrevVal),
-									s.printImmediateValue(result),
-									ipLog,
-									flagsNewVal,
-								),
-							},
-						)
-					} else {
-						results = append(
-							results,
-							&Result{
-								Text: fmt.Sprintf(
-									"%s ; %s:0x%x->0x%x%s",
-									ins.Text,
-									ins.DestRegister,
-									s.printImmediateValue(destPrevVal),
-									s.printImmediateValue(result),
-									ipLog,
-								),
-							},
-						)
-					}
-				}
-			default:
-				return nil, fmt.Errorf("unsupported operand type: %d", ins.OperandType)
-			}
-		case instruction.JNZ:
-			if !s.flags["Z"] {
-				updatedIPRegister := ins.IPRegister + ins.Immediate.Value
-				i = ipIdxMap[updatedIPRegister]
-				i++
-				ipLog := s.updateIPRegister(updatedIPRegister)
-				results = append(
-					results,
-					&Result{
-						Text: fmt.Sprintf(
-							"jne $-%d ;%s",
-							updatedIPRegister,
-							ipLog,
-						),
-					})
-				continue
-			} else {
-				s.Registers["ip"] = bits.Uint16ToBytes(uint16(ins.IPRegister))
-			}
-		default:
-			return nil, fmt.Errorf("unsupported instruction: %s", ins.Op)
+	vector, atStub := s.pendingHandler()
+	if atStub && !s.handlerDone {
+		return s.runHandler(vector)
+	}
+
+	// no interrupt comes between a handler and its stub's IRET, or the handler would run again when
+	// the interrupt returns to the stub
+	if s.flags["I"] && !s.interruptShadow && !atStub {
+		if irq, ok := s.pic.pending(); ok {
+			return s.hardwareInterrupt(irq), nil
 		}
-		i++
+	}
 
+	if breakpoint, ok := s.breakpoints[memory.Address(s.readRegister("cs"), s.readRegister("ip"))]; ok {
+		if err := breakpoint(); err != nil {
+			return nil, err
+		}
 	}
 
-	return results, nil
+	ins, err := s.fetch()
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := s.execute(ins)
+	if atStub {
+		s.handlerDone = false
+	}
+	return result, err
+}
+
+// SetBreakpoint calls fn each time the instruction at the physical address is about to execute, e.g.
+// to export a framebuffer part way through a run. An error from fn fails the step. Passing a nil fn
+// clears the breakpoint. Breakpoints stay set across Init.
+func (s *Simulator) SetBreakpoint(addr uint32, fn func() error) {
+	if fn == nil {
+		delete(s.breakpoints, addr)
+		return
+	}
+	s.breakpoints[addr] = fn
 }
 
-func (s *Simulator) doArithmeticOp(ins *instruction.Instruction, destPrevVal []byte, sourceVal []byte, destRegister string) []byte {
+func (s *Simulator) insideProgram() bool {
+	addr := memory.Address(s.readRegister("cs"), s.readRegister("ip"))
+	return addr >= s.programStart && addr < s.programEnd
+}
+
+func (s *Simulator) fetch() (*instruction.Instruction, error) {
+	cs := s.readRegister("cs")
+	ip := s.readRegister("ip")
+	window := make([]byte, fetchWindow)
+	for i := range window {
+		window[i] = s.Memory.Read8(memory.Address(cs, ip+uint16(i)))
+	}
+
+	ins, err := s.decoder.DecodeAt(window, 0)
+	if err != nil {
+		return nil, fmt.Errorf("decoding at %04x:%04x: %w", cs, ip, err)
+	}
+	ins.IPRegister = int(ip + uint16(ins.Size))
+	return ins, nil
+}
+
+func (s *Simulator) execute(ins *instruction.Instruction) (*Result, error) {
+	registersPrevVal := s.snapshotRegisters()
+	flagsPrevVal := s.printFlags()
+	ipPrevVal := s.readRegister("ip")
+	start := memory.Address(s.readRegister("cs"), ipPrevVal)
+	clocks := s.instructionClocks(ins)
+	s.branchTaken = false
+	s.repetitions = 0
+	s.instructionBus = BusStats{}
+	s.portTrace = ""
+	// TF is sampled before the instruction runs, so the instruction that sets it is not trapped.
+	singleStep := s.flags["T"]
+	s.writeRegister("ip", uint16(ins.IPRegister))
+
 	switch ins.Op {
-	case instruction.ADD:
-		s.Registers[destRegister] = bits.Add(destPrevVal, sourceVal)
-		s.flags["Z"] = bits.IsZero(s.Registers[destRegister])
-		s.flags["S"] = bits.IsNegative(s.Registers[destRegister])
-		return s.Registers[destRegister]
-	case instruction.SUB:
-		s.Registers[destRegister] = bits.Sub(destPrevVal, sourceVal)
-		s.flags["Z"] = bits.IsZero(s.Registers[destRegister])
-		s.flags["S"] = bits.IsNegative(s.Registers[destRegister])
-		return s.Registers[destRegister]
-	case instruction.CMP:
-		res := bits.Sub(destPrevVal, sourceVal)
-		s.flags["Z"] = bits.IsZero(res)
-		s.flags["S"] = bits.IsNegative(res)
-		return destPrevVal // CMP doesn't modify the destination register
+	case instruction.MOV:
+		if err := s.doMov(ins); err != nil {
+			return nil, err
+		}
+	case instruction.ADD, instruction.SUB, instruction.CMP:
+		if err := s.doArithmetic(ins); err != nil {
+			return nil, err
+		}
+	case instruction.JNZ, instruction.JE, instruction.JL, instruction.JLE, instruction.JB, instruction.JBE,
+		instruction.JP, instruction.JO, instruction.JS, instruction.JNE, instruction.JNL, instruction.JG,
+		instruction.JNB, instruction.JA, instruction.JNP, instruction.JNO, instruction.JNS,
+		instruction.LOOP, instruction.LOOPZ, instruction.LOOPNZ, instruction.JCXZ:
+		if err := s.doJump(ins); err != nil {
+			return nil, err
+		}
+	case instruction.PUSH, instruction.PUSHF:
+		s.doPush(ins)
+	case instruction.POP, instruction.POPF:
+		s.doPop(ins)
+	case instruction.CALL, instruction.CALLF:
+		if err := s.doCall(ins); err != nil {
+			return nil, err
+		}
+	case instruction.JMP, instruction.JMPF:
+		if err := s.doJmp(ins); err != nil {
+			return nil, err
+		}
+	case instruction.RET, instruction.RETF:
+		s.doRet(ins)
+	case instruction.AND, instruction.OR, instruction.XOR, instruction.TEST:
+		if err := s.doLogical(ins); err != nil {
+			return nil, err
+		}
+	case instruction.NOT:
+		s.doNot(ins)
+	case instruction.NEG:
+		s.doNeg(ins)
+	case instruction.INC, instruction.DEC:
+		s.doIncDec(ins)
+	case instruction.SHL, instruction.SHR, instruction.SAR, instruction.ROL, instruction.ROR,
+		instruction.RCL, instruction.RCR:
+		if err := s.doShift(ins); err != nil {
+			return nil, err
+		}
+	case instruction.DAA, instruction.DAS:
+		s.doDecimalAdjust(ins)
+	case instruction.AAA, instruction.AAS:
+		s.doAsciiAdjust(ins)
+	case instruction.AAM:
+		s.doAAM(ins)
+	case instruction.AAD:
+		s.doAAD(ins)
+	case instruction.MUL, instruction.IMUL:
+		s.doMultiply(ins)
+	case instruction.DIV, instruction.IDIV:
+		s.doDivide(ins)
+	case instruction.MOVS, instruction.CMPS, instruction.SCAS, instruction.LODS, instruction.STOS:
+		s.doString(ins)
+	case instruction.INT, instruction.INT3, instruction.INTO:
+		s.doInterrupt(ins)
+	case instruction.IRET:
+		s.doIret()
+	case instruction.IN, instruction.OUT:
+		s.doPortIO(ins)
+	case instruction.CLI:
+		s.flags["I"] = false
+	case instruction.STI:
+		s.flags["I"] = true
+	case instruction.CLD:
+		s.flags["D"] = false
+	case instruction.STD:
+		s.flags["D"] = true
+	case instruction.HLT:
+		s.halted = true
+	default:
+		return nil, fmt.Errorf("unsupported instruction: %s", ins.Op)
+	}
+	if singleStep {
+		s.interrupt(SingleStepInterrupt)
 	}
+	s.interruptShadow = ins.Op == instruction.STI ||
+		(ins.Op == instruction.MOV || ins.Op == instruction.POP) && ins.DestRegister == "ss" && ins.DestAddr == ""
+
+	result := &Result{Bus: s.instructionBus}
+	s.bus.add(s.instructionBus)
+	s.outcomeClocks(ins, start, &clocks)
+	result.Clocks = clocks.total()
+	s.advanceClocks(result.Clocks)
+	text := s.instructionText(ins, ipPrevVal) + " ;"
+	if s.estimateClocks {
+		text += fmt.Sprintf(" Clocks: +%d = %d%s |", result.Clocks, s.totalClocks, clocks.breakdown())
+	}
+	result.Text = text + s.traceChanges(registersPrevVal, flagsPrevVal, ipPrevVal)
+	return result, nil
+}
+
+// hardwareInterrupt acknowledges a request from the PIC and enters its handler.
+func (s *Simulator) hardwareInterrupt(irq int) *Result {
+	registersPrevVal := s.snapshotRegisters()
+	flagsPrevVal := s.printFlags()
+	ipPrevVal := s.readRegister("ip")
+	s.instructionBus = BusStats{}
+	s.portTrace = ""
+
+	vector := s.pic.acknowledge(irq)
+	s.interrupt(vector)
+	s.queue.reset()
+
+	result := &Result{Bus: s.instructionBus, Clocks: hardwareInterruptClocks}
+	s.bus.add(s.instructionBus)
+	s.advanceClocks(result.Clocks)
+	text := fmt.Sprintf("irq %d (int %d) ;", irq, vector)
+	if s.estimateClocks {
+		text += fmt.Sprintf(" Clocks: +%d = %d |", result.Clocks, s.totalClocks)
+	}
+	result.Text = text + s.traceChanges(registersPrevVal, flagsPrevVal, ipPrevVal)
+	return result
+}
+
+// canWake reports whether a hardware interrupt can end a HLT: IF is set and the PIC has an unmasked
+// request or the timer will raise one.
+func (s *Simulator) canWake() bool {
+	if !s.flags["I"] {
+		return false
+	}
+	if _, ok := s.pic.pending(); ok {
+		return true
+	}
+	return s.pic.accepts(TimerIRQ) && s.pit.willFire()
+}
+
+// wake idles the CPU after a HLT until a hardware interrupt arrives, letting the clocks and the timer
+// run, then services the interrupt.
+func (s *Simulator) wake() (*Result, error) {
+	for {
+		if !s.canWake() {
+			return nil, fmt.Errorf("halted with no interrupt that can resume execution")
+		}
+		if irq, ok := s.pic.pending(); ok {
+			s.halted = false
+			return s.hardwareInterrupt(irq), nil
+		}
+		s.advanceClocks(s.pit.clocksToEdge())
+	}
+}
+
+// advanceClocks adds clocks to the running total and lets the timer catch up with them.
+func (s *Simulator) advanceClocks(clocks int) {
+	s.totalClocks += clocks
+	for range s.pit.advance(clocks) {
+		s.pic.raise(TimerIRQ)
+	}
+	s.uart.advance()
+}
+
+// traceChanges lists the registers, IP and flags that changed since the given snapshot.
+func (s *Simulator) traceChanges(registers map[string]uint16, flags string, ip uint16) string {
+	text := s.printRegisterChanges(registers) + s.portTrace
+	if s.printIPRegister {
+		text += fmt.Sprintf(" ip:0x%x->0x%x", ip, s.readRegister("ip"))
+	}
+	if flagsNewVal := s.printFlags(); flagsNewVal != flags {
+		text += fmt.Sprintf(" flags:%s->%s", flags, flagsNewVal)
+	}
+	return text
+}
+
+// instructionText returns the text printed for an executed instruction.
+func (s *Simulator) instructionText(ins *instruction.Instruction, ip uint16) string {
+	switch {
+	case ins.Op == instruction.MOV && ins.OperandType == instruction.OpTypeImmToReg && ins.DestAddr == "":
+		return fmt.Sprintf("%s %s, %d", ins.Op, ins.DestRegister, s.printImmediateValue(ins.Immediate.Raw))
+	case ins.Op == instruction.MOV && ins.OperandType == instruction.OpTypeImmToReg:
+		insType := "byte"
+		if ins.WBit {
+			insType = "word"
+		}
+		dest := ins.DestAddr
+		if ins.SegmentOverride != "" {
+			dest = ins.SegmentOverride + ":" + dest
+		}
+		if disp := memoryDisplacement(ins); disp != nil {
+			dest += fmt.Sprintf("+%d", s.printImmediateValue(disp))
+		}
+		return fmt.Sprintf("%s %s [%s], %d", ins.Op, insType, dest, s.printImmediateValue(ins.Immediate.Raw))
+	case ins.Op == instruction.JNZ:
+		return fmt.Sprintf("jne $%+d", ins.IPRegister+ins.Immediate.Value-int(ip))
+	case ins.OperandType == instruction.OpTypeJump:
+		return fmt.Sprintf("%s $%+d", ins.Op, ins.IPRegister+ins.Immediate.Value-int(ip))
+	default:
+		return ins.Text
+	}
+}
+
+func (s *Simulator) doMov(ins *instruction.Instruction) error {
+	dest, source, err := s.operands(ins)
+	if err != nil {
+		return err
+	}
+	s.writeOperand(dest, ins.WBit, s.readOperand(source, ins.WBit))
 	return nil
 }
 
+func (s *Simulator) doArithmetic(ins *instruction.Instruction) error {
+	dest, source, err := s.operands(ins)
+	if err != nil {
+		return err
+	}
+	destVal := s.readOperand(dest, ins.WBit)
+	sourceVal := s.readOperand(source, ins.WBit)
+	result := s.doArithmeticOp(ins, destVal, sourceVal)
+	if ins.Op != instruction.CMP {
+		s.writeOperand(dest, ins.WBit, result)
+	}
+	return nil
+}
+
+func (s *Simulator) doArithmeticOp(ins *instruction.Instruction, destVal uint16, sourceVal uint16) uint16 {
+	dest := uint32(destVal)
+	source := uint32(sourceVal)
+	var result uint32
+	switch ins.Op {
+	case instruction.ADD:
+		result = dest + source
+	case instruction.SUB, instruction.CMP:
+		result = dest - source
+	}
+	s.setArithmeticFlags(ins.Op != instruction.ADD, dest, source, result, ins.WBit)
+	return truncate(uint16(result), ins.WBit)
+}
+
 func (s *Simulator) printFlags() string {
 	flags := ""
 	for _, flag := range s.flagOrder {
@@ -385,3 +515,17 @@ func (s *Simulator) printFlags() string {
 	}
 	return flags
 }
+
+func truncate(value uint16, wide bool) uint16 {
+	if wide {
+		return value
+	}
+	return value & 0xFF
+}
+
+func isNegative(value uint16, wide bool) bool {
+	if wide {
+		return value&0x8000 != 0
+	}
+	return value&0x80 != 0
+}
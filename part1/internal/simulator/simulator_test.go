@@ -5,7 +5,6 @@ import (
 	"testing"
 
 	"github.com/8086-simulator/part1/internal/bits"
-	"github.com/8086-simulator/part1/internal/decoder"
 )
 
 func TestSimulatorListing43(t *testing.T) {
@@ -13,7 +12,6 @@ func TestSimulatorListing43(t *testing.T) {
 	if err != nil {
 		t.Fatalf("Error reading file: %v", err)
 	}
-	decoder := decoder.NewDecoder()
 	sim := NewSimulator(false)
 	sim.Init()
 	expectedLogs := []string{
@@ -35,14 +33,15 @@ func TestSimulatorListing43(t *testing.T) {
 		"bp": bits.Uint16ToBytes(6),
 		"si": bits.Uint16ToBytes(7),
 		"di": bits.Uint16ToBytes(8),
-		"ip": bits.Uint16ToBytes(0),
+		"es": bits.Uint16ToBytes(0),
+		"cs": bits.Uint16ToBytes(0),
+		"ss": bits.Uint16ToBytes(0),
+		"ds": bits.Uint16ToBytes(0),
+		"ip": bits.Uint16ToBytes(24),
 	}
 
-	instructions, err := decoder.Decode(content)
-	if err != nil {
-		t.Fatalf("Error decoding data: %v", err)
-	}
-	results, err := sim.Run(instructions)
+	sim.Load(content)
+	results, err := sim.Run()
 	if err != nil {
 		t.Fatalf("Error running instructions: %v", err)
 	}
@@ -78,7 +77,6 @@ func TestSimulatorListing44(t *testing.T) {
 	if err != nil {
 		t.Fatalf("Error reading file: %v", err)
 	}
-	decoder := decoder.NewDecoder()
 	sim := NewSimulator(false)
 	sim.Init()
 	expectedLogs := []string{
@@ -104,14 +102,15 @@ func TestSimulatorListing44(t *testing.T) {
 		"bp": bits.Uint16ToBytes(2),
 		"si": bits.Uint16ToBytes(3),
 		"di": bits.Uint16ToBytes(4),
-		"ip": bits.Uint16ToBytes(0),
+		"es": bits.Uint16ToBytes(0),
+		"cs": bits.Uint16ToBytes(0),
+		"ss": bits.Uint16ToBytes(0),
+		"ds": bits.Uint16ToBytes(0),
+		"ip": bits.Uint16ToBytes(28),
 	}
 
-	instructions, err := decoder.Decode(content)
-	if err != nil {
-		t.Fatalf("Error decoding data: %v", err)
-	}
-	results, err := sim.Run(instructions)
+	sim.Load(content)
+	results, err := sim.Run()
 	if err != nil {
 		t.Fatalf("Error running instructions: %v", err)
 	}
@@ -134,7 +133,6 @@ func TestSimulatorListing46(t *testing.T) {
 	if err != nil {
 		t.Fatalf("Error reading file: %v", err)
 	}
-	decoder := decoder.NewDecoder()
 	sim := NewSimulator(false)
 	sim.Init()
 	expectedLogs := []string{
@@ -145,7 +143,7 @@ func TestSimulatorListing46(t *testing.T) {
 		"mov bp, 999 ; bp:0x0->0x3e7",
 		"cmp bp, sp ; flags:S->",
 		"add bp, 1027 ; bp:0x3e7->0x7ea",
-		"sub bp, 2026 ; bp:0x7ea->0x0 flags:->Z",
+		"sub bp, 2026 ; bp:0x7ea->0x0 flags:->PZ",
 	}
 	expectedRegisters := map[string][]byte{
 		"ax": bits.Uint16ToBytes(0),
@@ -156,14 +154,15 @@ func TestSimulatorListing46(t *testing.T) {
 		"bp": bits.Uint16ToBytes(0),
 		"si": bits.Uint16ToBytes(0),
 		"di": bits.Uint16ToBytes(0),
-		"ip": bits.Uint16ToBytes(0),
+		"es": bits.Uint16ToBytes(0),
+		"cs": bits.Uint16ToBytes(0),
+		"ss": bits.Uint16ToBytes(0),
+		"ds": bits.Uint16ToBytes(0),
+		"ip": bits.Uint16ToBytes(24),
 	}
 
-	instructions, err := decoder.Decode(content)
-	if err != nil {
-		t.Fatalf("Error decoding data: %v", err)
-	}
-	results, err := sim.Run(instructions)
+	sim.Load(content)
+	results, err := sim.Run()
 	if err != nil {
 		t.Fatalf("Error running instructions: %v", err)
 	}
@@ -186,15 +185,14 @@ func TestSimulatorListing48(t *testing.T) {
 	if err != nil {
 		t.Fatalf("Error reading file: %v", err)
 	}
-	decoder := decoder.NewDecoder()
 	sim := NewSimulator(true)
 	sim.Init()
 	expectedLogs := []string{
 		"mov cx, 200 ; cx:0x0->0xc8 ip:0x0->0x3",
 		"mov bx, cx ; bx:0x0->0xc8 ip:0x3->0x5",
-		"add cx, 1000 ; cx:0xc8->0x4b0 ip:0x5->0x9",
+		"add cx, 1000 ; cx:0xc8->0x4b0 ip:0x5->0x9 flags:->A",
 		"mov bx, 2000 ; bx:0xc8->0x7d0 ip:0x9->0xc",
-		"sub cx, bx ; cx:0x4b0->0xfce0 ip:0xc->0xe flags:->S",
+		"sub cx, bx ; cx:0x4b0->0xfce0 ip:0xc->0xe flags:A->CS",
 	}
 	expectedRegisters := map[string][]byte{
 		"ax": bits.Uint16ToBytes(0),
@@ -205,18 +203,20 @@ func TestSimulatorListing48(t *testing.T) {
 		"bp": bits.Uint16ToBytes(0),
 		"si": bits.Uint16ToBytes(0),
 		"di": bits.Uint16ToBytes(0),
+		"es": bits.Uint16ToBytes(0),
+		"cs": bits.Uint16ToBytes(0),
+		"ss": bits.Uint16ToBytes(0),
+		"ds": bits.Uint16ToBytes(0),
 		"ip": bits.Uint16ToBytes(14),
 	}
 	expectedFlags := map[string]bool{
+		"C": true,
 		"Z": false,
 		"S": true,
 	}
 
-	instructions, err := decoder.Decode(content)
-	if err != nil {
-		t.Fatalf("Error decoding data: %v", err)
-	}
-	results, err := sim.Run(instructions)
+	sim.Load(content)
+	results, err := sim.Run()
 	if err != nil {
 		t.Fatalf("Error running instructions: %v", err)
 	}
@@ -245,20 +245,19 @@ func TestSimulatorListing49(t *testing.T) {
 	if err != nil {
 		t.Fatalf("Error reading file: %v", err)
 	}
-	decoder := decoder.NewDecoder()
 	sim := NewSimulator(true)
 	sim.Init()
 	expectedLogs := []string{
 		"mov cx, 3 ; cx:0x0->0x3 ip:0x0->0x3",
 		"mov bx, 1000 ; bx:0x0->0x3e8 ip:0x3->0x6",
-		"add bx, 10 ; bx:0x3e8->0x3f2 ip:0x6->0x9",
-		"sub cx, 1 ; cx:0x3->0x2 ip:0x9->0xc",
+		"add bx, 10 ; bx:0x3e8->0x3f2 ip:0x6->0x9 flags:->A",
+		"sub cx, 1 ; cx:0x3->0x2 ip:0x9->0xc flags:A->",
 		"jne $-6 ; ip:0xc->0x6",
-		"add bx, 10 ; bx:0x3f2->0x3fc ip:0x6->0x9",
-		"sub cx, 1 ; cx:0x2->0x1 ip:0x9->0xc",
+		"add bx, 10 ; bx:0x3f2->0x3fc ip:0x6->0x9 flags:->P",
+		"sub cx, 1 ; cx:0x2->0x1 ip:0x9->0xc flags:P->",
 		"jne $-6 ; ip:0xc->0x6",
-		"add bx, 10 ; bx:0x3fc->0x406 ip:0x6->0x9",
-		"sub cx, 1 ; cx:0x1->0x0 ip:0x9->0xc flags:->Z",
+		"add bx, 10 ; bx:0x3fc->0x406 ip:0x6->0x9 flags:->PA",
+		"sub cx, 1 ; cx:0x1->0x0 ip:0x9->0xc flags:PA->PZ",
 		"jne $-6 ; ip:0xc->0xe",
 	}
 	expectedRegisters := map[string][]byte{
@@ -270,18 +269,20 @@ func TestSimulatorListing49(t *testing.T) {
 		"bp": bits.Uint16ToBytes(0),
 		"si": bits.Uint16ToBytes(0),
 		"di": bits.Uint16ToBytes(0),
+		"es": bits.Uint16ToBytes(0),
+		"cs": bits.Uint16ToBytes(0),
+		"ss": bits.Uint16ToBytes(0),
+		"ds": bits.Uint16ToBytes(0),
 		"ip": bits.Uint16ToBytes(14),
 	}
 	expectedFlags := map[string]bool{
+		"P": true,
 		"Z": true,
 		"S": false,
 	}
 
-	instructions, err := decoder.Decode(content)
-	if err != nil {
-		t.Fatalf("Error decoding data: %v", err)
-	}
-	results, err := sim.Run(instructions)
+	sim.Load(content)
+	results, err := sim.Run()
 	if err != nil {
 		t.Fatalf("Error running instructions: %v", err)
 	}
@@ -318,7 +319,6 @@ func TestSimulatorListing51(t *testing.T) {
 	if err != nil {
 		t.Fatalf("Error reading file: %v", err)
 	}
-	decoder := decoder.NewDecoder()
 	sim := NewSimulator(true)
 	sim.Init()
 	expectedLogs := []string{
@@ -342,14 +342,15 @@ func TestSimulatorListing51(t *testing.T) {
 		"bp": bits.Uint16ToBytes(4),
 		"si": bits.Uint16ToBytes(0),
 		"di": bits.Uint16ToBytes(0),
+		"es": bits.Uint16ToBytes(0),
+		"cs": bits.Uint16ToBytes(0),
+		"ss": bits.Uint16ToBytes(0),
+		"ds": bits.Uint16ToBytes(0),
 		"ip": bits.Uint16ToBytes(48),
 	}
 
-	instructions, err := decoder.Decode(content)
-	if err != nil {
-		t.Fatalf("Error decoding data: %v", err)
-	}
-	results, err := sim.Run(instructions)
+	sim.Load(content)
+	results, err := sim.Run()
 	if err != nil {
 		t.Fatalf("Error running instructions: %v", err)
 	}
@@ -371,3 +372,35 @@ func TestSimulatorListing51(t *testing.T) {
 		}
 	}
 }
+
+func TestSimulatorHalt(t *testing.T) {
+	sim := NewSimulator(true)
+	sim.Init()
+	expectedLogs := []string{
+		"mov cx, 3 ; cx:0x0->0x3 ip:0x0->0x3",
+		"hlt ; ip:0x3->0x4",
+	}
+
+	// mov cx, 3; hlt; mov cx, 4
+	sim.Load([]byte{0xb9, 0x03, 0x00, 0xf4, 0xb9, 0x04, 0x00})
+	results, err := sim.Run()
+	if err != nil {
+		t.Fatalf("Error running instructions: %v", err)
+	}
+
+	if len(results) != len(expectedLogs) {
+		t.Fatalf("Expected %d instructions but got %d", len(expectedLogs), len(results))
+	}
+	for i, result := range results {
+		if result.Text != expectedLogs[i] {
+			t.Fatalf("Expected instruction %s but got %s", expectedLogs[i], result.Text)
+		}
+	}
+
+	if !sim.Halted() {
+		t.Fatalf("Expected simulator to be halted")
+	}
+	if !registerValueEquals(t, sim.Registers["cx"], bits.Uint16ToBytes(3)) {
+		t.Fatalf("Expected register cx to be 3 but got %d", sim.Registers["cx"])
+	}
+}
@@ -0,0 +1,118 @@
+package simulator
+
+import (
+	"encoding/binary"
+	"io"
+)
+
+const (
+	// speakerPort is port B of the 8255 PPI. Bit 0 gates PIT channel 2 and bit 1 lets its output
+	// drive the speaker.
+	speakerPort uint16 = 0x61
+	speakerGate        = 0x01
+	speakerData        = 0x02
+	// cpuClockHz is the 4.77 MHz clock of the PC, a third of its 14.31818 MHz crystal.
+	cpuClockHz = 14318180 / 3
+	// wavSampleRate is the sample rate of the rendered audio.
+	wavSampleRate = 44100
+	// speakerHigh and speakerLow are the 8-bit unsigned samples for the two cone positions.
+	speakerHigh = 0xC0
+	speakerLow  = 0x40
+)
+
+// speakerEvent is a change of the speaker's input at a point in simulated time.
+type speakerEvent struct {
+	clock   int
+	control byte
+	// period is the square wave period of PIT channel 2 in ticks when it drives the speaker, 0 when
+	// the speaker follows the data bit alone.
+	period int
+}
+
+// level returns whether the speaker cone is out at a clock after the event.
+func (e speakerEvent) level(clock int) bool {
+	if e.control&speakerData == 0 {
+		return false
+	}
+	if e.period == 0 {
+		// with the gate low channel 2 holds its output high
+		return true
+	}
+	ticks := (clock - e.clock) / clocksPerPITTick
+	return ticks%e.period < (e.period+1)/2
+}
+
+// speaker records what drives the PC speaker over time. It is the port 61h device.
+type speaker struct {
+	control byte
+	pit     *pit
+	// clock points at the simulator's running clock count.
+	clock  *int
+	events []speakerEvent
+}
+
+func (sp *speaker) In(port uint16) byte {
+	return sp.control
+}
+
+func (sp *speaker) Out(port uint16, value byte) {
+	sp.control = value
+	sp.record()
+}
+
+// record adds an event when the speaker's input changed. It runs on writes to port 61h and when
+// channel 2 is reprogrammed.
+func (sp *speaker) record() {
+	channel := &sp.pit.channels[2]
+	event := speakerEvent{clock: *sp.clock, control: sp.control & (speakerGate | speakerData)}
+	if event.control == speakerGate|speakerData && channel.counting && channel.mode == 3 {
+		event.period = channel.period()
+	}
+	if n := len(sp.events); n > 0 && sp.events[n-1].control == event.control && sp.events[n-1].period == event.period {
+		return
+	}
+	sp.events = append(sp.events, event)
+}
+
+// WriteSpeakerWAV renders the PC speaker output from the start of the run to the current clock as an
+// 8-bit mono WAV file.
+func (s *Simulator) WriteSpeakerWAV(w io.Writer) error {
+	samples := make([]byte, int64(s.totalClocks)*wavSampleRate/cpuClockHz)
+	events := s.speaker.events
+	for i := range samples {
+		clock := int(int64(i) * cpuClockHz / wavSampleRate)
+		for len(events) > 1 && events[1].clock <= clock {
+			events = events[1:]
+		}
+		samples[i] = speakerLow
+		if len(events) > 0 && events[0].clock <= clock && events[0].level(clock) {
+			samples[i] = speakerHigh
+		}
+	}
+
+	header := struct {
+		Riff          [4]byte
+		Size          uint32
+		Wave          [4]byte
+		Fmt           [4]byte
+		FmtSize       uint32
+		Format        uint16
+		Channels      uint16
+		SampleRate    uint32
+		ByteRate      uint32
+		BlockAlign    uint16
+		BitsPerSample uint16
+		Data          [4]byte
+		DataSize      uint32
+	}{
+		Riff: [4]byte{'R', 'I', 'F', 'F'}, Size: uint32(36 + len(samples)), Wave: [4]byte{'W', 'A', 'V', 'E'},
+		Fmt: [4]byte{'f', 'm', 't', ' '}, FmtSize: 16, Format: 1, Channels: 1,
+		SampleRate: wavSampleRate, ByteRate: wavSampleRate, BlockAlign: 1, BitsPerSample: 8,
+		Data: [4]byte{'d', 'a', 't', 'a'}, DataSize: uint32(len(samples)),
+	}
+	if err := binary.Write(w, binary.LittleEndian, header); err != nil {
+		return err
+	}
+	_, err := w.Write(samples)
+	return err
+}
@@ -0,0 +1,65 @@
+package simulator
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+)
+
+func TestSimulatorSpeakerWAV(t *testing.T) {
+	tests := []struct {
+		name    string
+		enable  byte
+		minEdge int
+		maxEdge int
+	}{
+		// 1193 PIT ticks is close to 1 kHz, and the loop runs for about 36 ms
+		{name: "square wave from channel 2", enable: 0x03, minEdge: 66, maxEdge: 76},
+		{name: "gate open but speaker off", enable: 0x01, minEdge: 0, maxEdge: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sim := NewSimulator(false)
+			sim.Load(append(pitProgram(2, 0xb6, 1193),
+				0xe4, 0x61, // in al, 97
+				0x0c, tt.enable, // or al, enable
+				0xe6, 0x61, // out 97, al
+				0xb9, 0x10, 0x27, // mov cx, 10000
+				0xe2, 0xfe, // loop $
+				0x24, 0xfc, // and al, 0xfc
+				0xe6, 0x61, // out 97, al
+				0xf4, // hlt
+			))
+			if _, err := sim.Run(); err != nil {
+				t.Fatalf("Error running instructions: %v", err)
+			}
+
+			var out bytes.Buffer
+			if err := sim.WriteSpeakerWAV(&out); err != nil {
+				t.Fatalf("Error writing WAV: %v", err)
+			}
+			wav := out.Bytes()
+			if string(wav[0:4]) != "RIFF" || string(wav[8:12]) != "WAVE" || string(wav[36:40]) != "data" {
+				t.Fatalf("Expected a RIFF WAVE header but got % x", wav[:44])
+			}
+			samples := wav[44:]
+			if size := binary.LittleEndian.Uint32(wav[40:]); int(size) != len(samples) {
+				t.Fatalf("Expected %d data bytes but got %d", size, len(samples))
+			}
+			if expected := sim.Clocks() * wavSampleRate / cpuClockHz; len(samples) != expected {
+				t.Fatalf("Expected %d samples but got %d", expected, len(samples))
+			}
+
+			edges := 0
+			for i := 1; i < len(samples); i++ {
+				if samples[i] != samples[i-1] {
+					edges++
+				}
+			}
+			if edges < tt.minEdge || edges > tt.maxEdge {
+				t.Fatalf("Expected between %d and %d edges but got %d", tt.minEdge, tt.maxEdge, edges)
+			}
+		})
+	}
+}
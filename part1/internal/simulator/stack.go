@@ -0,0 +1,107 @@
+package simulator
+
+import (
+	"fmt"
+
+	"github.com/8086-simulator/part1/internal/bits"
+	"github.com/8086-simulator/part1/internal/instruction"
+)
+
+// push decrements SP and stores a word at SS:SP.
+func (s *Simulator) push(value uint16) {
+	sp := s.readRegister("sp") - 2
+	s.writeRegister("sp", sp)
+	s.writeMemory("ss", sp, true, value)
+}
+
+// pop loads the word at SS:SP and increments SP.
+func (s *Simulator) pop() uint16 {
+	sp := s.readRegister("sp")
+	value := s.readMemory("ss", sp, true)
+	s.writeRegister("sp", sp+2)
+	return value
+}
+
+func (s *Simulator) doPush(ins *instruction.Instruction) {
+	if ins.Op == instruction.PUSHF {
+		s.push(s.flagsWord())
+		return
+	}
+	// The 8086 decrements SP before reading the operand, so PUSH SP stores the new value.
+	sp := s.readRegister("sp") - 2
+	s.writeRegister("sp", sp)
+	s.writeMemory("ss", sp, true, s.readOperand(s.destOperand(ins), true))
+}
+
+func (s *Simulator) doPop(ins *instruction.Instruction) {
+	value := s.pop()
+	if ins.Op == instruction.POPF {
+		s.setFlagsWord(value)
+		return
+	}
+	s.writeOperand(s.destOperand(ins), true, value)
+}
+
+// doCall executes near and far CALL; the return address is the already advanced IP.
+func (s *Simulator) doCall(ins *instruction.Instruction) error {
+	segment, offset, err := s.transferTarget(ins)
+	if err != nil {
+		return err
+	}
+	if ins.Op == instruction.CALLF {
+		s.push(s.readRegister("cs"))
+	}
+	s.push(s.readRegister("ip"))
+	if ins.Op == instruction.CALLF {
+		s.writeRegister("cs", segment)
+	}
+	s.writeRegister("ip", offset)
+	return nil
+}
+
+func (s *Simulator) doJmp(ins *instruction.Instruction) error {
+	segment, offset, err := s.transferTarget(ins)
+	if err != nil {
+		return err
+	}
+	if ins.Op == instruction.JMPF {
+		s.writeRegister("cs", segment)
+	}
+	s.writeRegister("ip", offset)
+	return nil
+}
+
+// doRet pops IP (and CS for RETF), then releases the optional immediate number of bytes of arguments.
+func (s *Simulator) doRet(ins *instruction.Instruction) {
+	s.writeRegister("ip", s.pop())
+	if ins.Op == instruction.RETF {
+		s.writeRegister("cs", s.pop())
+	}
+	if ins.Immediate != nil {
+		s.writeRegister("sp", s.readRegister("sp")+uint16(ins.Immediate.Value))
+	}
+}
+
+// transferTarget resolves the segment and offset a CALL or JMP transfers control to.
+func (s *Simulator) transferTarget(ins *instruction.Instruction) (uint16, uint16, error) {
+	cs := s.readRegister("cs")
+	switch ins.OperandType {
+	case instruction.OpTypeJump:
+		return cs, uint16(ins.IPRegister + ins.Immediate.Value), nil
+	case instruction.OpTypeFarJump:
+		raw := ins.Immediate.Raw
+		return bits.ToUnsigned16(raw[2], raw[3]), bits.ToUnsigned16(raw[0], raw[1]), nil
+	case instruction.OpTypeRegMem:
+		target := s.destOperand(ins)
+		if ins.Op == instruction.CALLF || ins.Op == instruction.JMPF {
+			if target.kind != operandMemory {
+				return 0, 0, fmt.Errorf("%s requires a memory operand", ins.Op)
+			}
+			offset := s.readMemory(target.segment, target.offset, true)
+			segment := s.readMemory(target.segment, target.offset+2, true)
+			return segment, offset, nil
+		}
+		return cs, s.readOperand(target, true), nil
+	}
+	return 0, 0, fmt.Errorf("unsupported operand type: %d", ins.OperandType)
+}
@@ -0,0 +1,141 @@
+package simulator
+
+import (
+	"testing"
+
+	"github.com/8086-simulator/part1/internal/memory"
+)
+
+func TestSimulatorCallRet(t *testing.T) {
+	sim := NewSimulator(true)
+	sim.Init()
+	expectedLogs := []string{
+		"mov ax, 4096 ; ax:0x0->0x1000 ip:0x0->0x3",
+		"mov ss, ax ; ss:0x0->0x1000 ip:0x3->0x5",
+		"mov sp, 256 ; sp:0x0->0x100 ip:0x5->0x8",
+		"mov bx, 7 ; bx:0x0->0x7 ip:0x8->0xb",
+		"push bx ; sp:0x100->0xfe ip:0xb->0xc",
+		"call $+5 ; sp:0xfe->0xfc ip:0xc->0x11",
+		"push bp ; sp:0xfc->0xfa ip:0x11->0x12",
+		"mov bp, sp ; bp:0x0->0xfa ip:0x12->0x14",
+		"mov ax, [bp + 4] ; ax:0x1000->0x7 ip:0x14->0x17",
+		"add ax, 1 ; ax:0x7->0x8 ip:0x17->0x1a",
+		"pop bp ; sp:0xfa->0xfc bp:0xfa->0x0 ip:0x1a->0x1b",
+		"ret 2 ; sp:0xfc->0x100 ip:0x1b->0xf",
+		"hlt ; ip:0xf->0x10",
+	}
+
+	sim.Load([]byte{
+		0xb8, 0x00, 0x10, // mov ax, 0x1000
+		0x8e, 0xd0, // mov ss, ax
+		0xbc, 0x00, 0x01, // mov sp, 0x100
+		0xbb, 0x07, 0x00, // mov bx, 7
+		0x53,             // push bx
+		0xe8, 0x02, 0x00, // call sub
+		0xf4,       // hlt
+		0x90,       // (skipped)
+		0x55,       // sub: push bp
+		0x89, 0xe5, // mov bp, sp
+		0x8b, 0x46, 0x04, // mov ax, [bp + 4]
+		0x83, 0xc0, 0x01, // add ax, 1
+		0x5d,             // pop bp
+		0xc2, 0x02, 0x00, // ret 2
+	})
+	results, err := sim.Run()
+	if err != nil {
+		t.Fatalf("Error running instructions: %v", err)
+	}
+
+	if len(results) != len(expectedLogs) {
+		t.Fatalf("Expected %d instructions but got %d", len(expectedLogs), len(results))
+	}
+	for i, result := range results {
+		if result.Text != expectedLogs[i] {
+			t.Fatalf("\nExpected instruction: %s\n                 Got: %s", expectedLogs[i], result.Text)
+		}
+	}
+
+	if got := sim.Memory.Read8(memory.Address(0x1000, 0xfe)); got != 7 {
+		t.Fatalf("Expected pushed argument 7 at ss:0xfe but got %d", got)
+	}
+}
+
+func TestSimulatorStackOperations(t *testing.T) {
+	tests := []struct {
+		name     string
+		program  []byte
+		register string
+		expected uint16
+	}{
+		{
+			// mov sp, 0x100; push sp; pop ax
+			name:     "push sp stores decremented value",
+			program:  []byte{0xbc, 0x00, 0x01, 0x54, 0x58},
+			register: "ax",
+			expected: 0xfe,
+		},
+		{
+			// mov sp, 0x100; mov ax, 0x1234; push ax; pop ds
+			name:     "pop segment register",
+			program:  []byte{0xbc, 0x00, 0x01, 0xb8, 0x34, 0x12, 0x50, 0x1f},
+			register: "ds",
+			expected: 0x1234,
+		},
+		{
+			// mov sp, 0x100; mov word [0x200], 0xbeef; push word [0x200]; pop dx
+			name:     "push memory",
+			program:  []byte{0xbc, 0x00, 0x01, 0xc7, 0x06, 0x00, 0x02, 0xef, 0xbe, 0xff, 0x36, 0x00, 0x02, 0x5a},
+			register: "dx",
+			expected: 0xbeef,
+		},
+		{
+			// mov sp, 0x100; mov ax, 5; push ax; pop word [0x200]; mov bx, [0x200]
+			name:     "pop memory",
+			program:  []byte{0xbc, 0x00, 0x01, 0xb8, 0x05, 0x00, 0x50, 0x8f, 0x06, 0x00, 0x02, 0x8b, 0x1e, 0x00, 0x02},
+			register: "bx",
+			expected: 5,
+		},
+		{
+			// mov sp, 0x100; mov ax, 1; cmp ax, 2; pushf; pop cx
+			name:     "pushf",
+			program:  []byte{0xbc, 0x00, 0x01, 0xb8, 0x01, 0x00, 0x3d, 0x02, 0x00, 0x9c, 0x59},
+			register: "cx",
+			expected: 0xf097,
+		},
+		{
+			// mov sp, 0x100; mov ax, 0x08c1; push ax; popf; pushf; pop cx
+			name:     "popf",
+			program:  []byte{0xbc, 0x00, 0x01, 0xb8, 0xc1, 0x08, 0x50, 0x9d, 0x9c, 0x59},
+			register: "cx",
+			expected: 0xf8c3,
+		},
+		{
+			// mov sp, 0x100; call far 0:10; hlt; (padding); far: mov bx, 9; retf
+			name:     "far call",
+			program:  []byte{0xbc, 0x00, 0x01, 0x9a, 0x0a, 0x00, 0x00, 0x00, 0xf4, 0x90, 0xbb, 0x09, 0x00, 0xcb},
+			register: "bx",
+			expected: 9,
+		},
+		{
+			// mov bx, 8; jmp bx; mov cx, 1; (target) mov dx, 2
+			name:     "jmp register",
+			program:  []byte{0xbb, 0x08, 0x00, 0xff, 0xe3, 0xb9, 0x01, 0x00, 0xba, 0x02, 0x00},
+			register: "cx",
+			expected: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sim := NewSimulator(false)
+			sim.Load(tt.program)
+			if _, err := sim.Run(); err != nil {
+				t.Fatalf("Error running instructions: %v", err)
+			}
+
+			if got := sim.readRegister(tt.register); got != tt.expected {
+				t.Fatalf("Expected %s to be 0x%x but got 0x%x", tt.register, tt.expected, got)
+			}
+		})
+	}
+}
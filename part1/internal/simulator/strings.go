@@ -0,0 +1,80 @@
+package simulator
+
+import (
+	"github.com/8086-simulator/part1/internal/instruction"
+)
+
+// doString executes a string instruction, repeating it while CX is non-zero when a REP prefix is present.
+// REPE/REPZ and REPNE/REPNZ additionally stop CMPS and SCAS once ZF no longer matches the prefix.
+func (s *Simulator) doString(ins *instruction.Instruction) {
+	if ins.Rep == "" {
+		s.doStringOnce(ins)
+		return
+	}
+
+	for s.readRegister("cx") != 0 {
+		s.doStringOnce(ins)
+		s.repetitions++
+		s.writeRegister("cx", s.readRegister("cx")-1)
+		if ins.Op == instruction.CMPS || ins.Op == instruction.SCAS {
+			if ins.Rep == "rep" && !s.flags["Z"] || ins.Rep == "repne" && s.flags["Z"] {
+				break
+			}
+		}
+	}
+}
+
+// doStringOnce performs a single iteration. The source is DS:SI unless a segment override is given;
+// the destination is always ES:DI.
+func (s *Simulator) doStringOnce(ins *instruction.Instruction) {
+	sourceSegment := "ds"
+	if ins.SegmentOverride != "" {
+		sourceSegment = ins.SegmentOverride
+	}
+	si := s.readRegister("si")
+	di := s.readRegister("di")
+
+	switch ins.Op {
+	case instruction.MOVS:
+		s.writeMemory("es", di, ins.WBit, s.readMemory(sourceSegment, si, ins.WBit))
+		s.advanceStringRegister("si", ins.WBit)
+		s.advanceStringRegister("di", ins.WBit)
+	case instruction.CMPS:
+		dest := uint32(s.readMemory(sourceSegment, si, ins.WBit))
+		source := uint32(s.readMemory("es", di, ins.WBit))
+		s.setArithmeticFlags(true, dest, source, dest-source, ins.WBit)
+		s.advanceStringRegister("si", ins.WBit)
+		s.advanceStringRegister("di", ins.WBit)
+	case instruction.SCAS:
+		dest := uint32(s.readRegister(accumulator(ins.WBit)))
+		source := uint32(s.readMemory("es", di, ins.WBit))
+		s.setArithmeticFlags(true, dest, source, dest-source, ins.WBit)
+		s.advanceStringRegister("di", ins.WBit)
+	case instruction.LODS:
+		s.writeRegister(accumulator(ins.WBit), s.readMemory(sourceSegment, si, ins.WBit))
+		s.advanceStringRegister("si", ins.WBit)
+	case instruction.STOS:
+		s.writeMemory("es", di, ins.WBit, s.readRegister(accumulator(ins.WBit)))
+		s.advanceStringRegister("di", ins.WBit)
+	}
+}
+
+// advanceStringRegister steps SI or DI by the element size, backwards when DF is set.
+func (s *Simulator) advanceStringRegister(name string, wide bool) {
+	step := uint16(1)
+	if wide {
+		step = 2
+	}
+	if s.flags["D"] {
+		s.writeRegister(name, s.readRegister(name)-step)
+	} else {
+		s.writeRegister(name, s.readRegister(name)+step)
+	}
+}
+
+func accumulator(wide bool) string {
+	if wide {
+		return "ax"
+	}
+	return "al"
+}
@@ -0,0 +1,104 @@
+package simulator
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestSimulatorStringInstructions(t *testing.T) {
+	tests := []struct {
+		name              string
+		program           []byte
+		data              map[uint32][]byte
+		expectedRegisters map[string]uint16
+		expectedMemory    map[uint32][]byte
+	}{
+		{
+			// mov si, 0x200; mov di, 0x300; mov cx, 4; cld; rep movsb
+			name:              "rep movsb",
+			program:           []byte{0xbe, 0x00, 0x02, 0xbf, 0x00, 0x03, 0xb9, 0x04, 0x00, 0xfc, 0xf3, 0xa4},
+			data:              map[uint32][]byte{0x200: []byte("ABCD")},
+			expectedRegisters: map[string]uint16{"si": 0x204, "di": 0x304, "cx": 0},
+			expectedMemory:    map[uint32][]byte{0x300: []byte("ABCD")},
+		},
+		{
+			// std; mov si, 0x203; mov di, 0x303; mov cx, 4; rep movsb
+			name:              "rep movsb backwards",
+			program:           []byte{0xfd, 0xbe, 0x03, 0x02, 0xbf, 0x03, 0x03, 0xb9, 0x04, 0x00, 0xf3, 0xa4},
+			data:              map[uint32][]byte{0x200: []byte("ABCD")},
+			expectedRegisters: map[string]uint16{"si": 0x1ff, "di": 0x2ff, "cx": 0},
+			expectedMemory:    map[uint32][]byte{0x300: []byte("ABCD")},
+		},
+		{
+			// mov ax, 0xabcd; mov di, 0x300; mov cx, 3; rep stosw
+			name:              "rep stosw",
+			program:           []byte{0xb8, 0xcd, 0xab, 0xbf, 0x00, 0x03, 0xb9, 0x03, 0x00, 0xf3, 0xab},
+			expectedRegisters: map[string]uint16{"di": 0x306, "cx": 0},
+			expectedMemory:    map[uint32][]byte{0x300: {0xcd, 0xab, 0xcd, 0xab, 0xcd, 0xab}},
+		},
+		{
+			// mov si, 0x200; mov di, 0x300; mov cx, 4; repe cmpsb
+			name:              "repe cmpsb stops on mismatch",
+			program:           []byte{0xbe, 0x00, 0x02, 0xbf, 0x00, 0x03, 0xb9, 0x04, 0x00, 0xf3, 0xa6},
+			data:              map[uint32][]byte{0x200: []byte("ABCD"), 0x300: []byte("ABXD")},
+			expectedRegisters: map[string]uint16{"si": 0x203, "di": 0x303, "cx": 1},
+		},
+		{
+			// mov al, 'L'; mov di, 0x300; mov cx, 5; repne scasb
+			name:              "repne scasb stops on match",
+			program:           []byte{0xb0, 0x4c, 0xbf, 0x00, 0x03, 0xb9, 0x05, 0x00, 0xf2, 0xae},
+			data:              map[uint32][]byte{0x300: []byte("HELLO")},
+			expectedRegisters: map[string]uint16{"di": 0x303, "cx": 2},
+		},
+		{
+			// mov si, 0x200; lodsw
+			name:              "lodsw",
+			program:           []byte{0xbe, 0x00, 0x02, 0xad},
+			data:              map[uint32][]byte{0x200: {0x34, 0x12}},
+			expectedRegisters: map[string]uint16{"ax": 0x1234, "si": 0x202},
+		},
+		{
+			// mov di, 0x300; rep stosb (cx is zero)
+			name:              "rep with zero count",
+			program:           []byte{0xbf, 0x00, 0x03, 0xf3, 0xaa},
+			expectedRegisters: map[string]uint16{"di": 0x300, "cx": 0},
+		},
+		{
+			// mov ax, 0x10; mov es, ax; mov si, 0x100; mov di, 0x200; es movsb
+			name:              "segment override on source",
+			program:           []byte{0xb8, 0x10, 0x00, 0x8e, 0xc0, 0xbe, 0x00, 0x01, 0xbf, 0x00, 0x02, 0x26, 0xa4},
+			data:              map[uint32][]byte{0x100: {0x11}, 0x200: {0x42}},
+			expectedRegisters: map[string]uint16{"si": 0x101, "di": 0x201},
+			expectedMemory:    map[uint32][]byte{0x300: {0x42}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sim := NewSimulator(false)
+			sim.writeRegister("cs", 0x1000)
+			sim.Load(tt.program)
+			for addr, data := range tt.data {
+				sim.Memory.Load(addr, data)
+			}
+			if _, err := sim.Run(); err != nil {
+				t.Fatalf("Error running instructions: %v", err)
+			}
+
+			for register, expected := range tt.expectedRegisters {
+				if got := sim.readRegister(register); got != expected {
+					t.Fatalf("Expected %s to be 0x%x but got 0x%x", register, expected, got)
+				}
+			}
+			for addr, expected := range tt.expectedMemory {
+				got := make([]byte, len(expected))
+				for i := range got {
+					got[i] = sim.Memory.Read8(addr + uint32(i))
+				}
+				if !bytes.Equal(got, expected) {
+					t.Fatalf("Expected memory at 0x%x to be %v but got %v", addr, expected, got)
+				}
+			}
+		})
+	}
+}
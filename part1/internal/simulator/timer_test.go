@@ -0,0 +1,139 @@
+package simulator
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSimulatorTimerInterrupts(t *testing.T) {
+	handler := []byte{
+		0x83, 0xc7, 0x01, // add di, 1
+		0xb0, 0x20, // mov al, 0x20
+		0xe6, 0x20, // out 32, al (non-specific EOI)
+		0xcf, // iret
+	}
+	tests := []struct {
+		name     string
+		setup    []byte
+		handler  []byte
+		expected uint16
+	}{
+		{
+			name:    "interrupts enabled",
+			setup:   []byte{0xfb}, // sti
+			handler: handler,
+			// about 3400 clocks of LOOP plus 103 clocks per handler run, one interrupt every 400 clocks
+			expected: 11,
+		},
+		{
+			name:     "interrupts disabled",
+			setup:    []byte{},
+			handler:  handler,
+			expected: 0,
+		},
+		{
+			name:     "irq0 masked",
+			setup:    []byte{0xb0, 0x01, 0xe6, 0x21, 0xfb}, // mov al, 1; out 33, al; sti
+			handler:  handler,
+			expected: 0,
+		},
+		{
+			name:     "no end of interrupt",
+			setup:    []byte{0xfb},                   // sti
+			handler:  []byte{0x83, 0xc7, 0x01, 0xcf}, // add di, 1; iret
+			expected: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sim := NewSimulator(false)
+			sim.writeRegister("cs", 0x100)
+			// a period of 100 ticks is 400 clocks
+			body := append(pitProgram(0, 0x34, 100), tt.setup...)
+			body = append(body,
+				0xb9, 0xc8, 0x00, // mov cx, 200
+				0xe2, 0xfe, // l: loop l
+				0xfa, // cli
+			)
+			sim.Load(interruptProgram(8, body, tt.handler))
+			results, err := sim.Run()
+			if err != nil {
+				t.Fatalf("Error running instructions: %v", err)
+			}
+
+			if di := sim.readRegister("di"); di != tt.expected {
+				t.Fatalf("Expected %d timer interrupts but got %d", tt.expected, di)
+			}
+			irqs := 0
+			for _, result := range results {
+				if strings.HasPrefix(result.Text, "irq 0 (int 8) ;") {
+					irqs++
+				}
+			}
+			if irqs != int(tt.expected) {
+				t.Fatalf("Expected %d irq trace lines but got %d", tt.expected, irqs)
+			}
+		})
+	}
+}
+
+func TestSimulatorTimerLatch(t *testing.T) {
+	sim := NewSimulator(false)
+	sim.Load(append(pitProgram(0, 0x34, 1000),
+		0xb0, 0x00, // mov al, 0 (latch channel 0)
+		0xe6, 0x43, // out 67, al
+		0xe4, 0x40, // in al, 64
+		0x88, 0xc3, // mov bl, al
+		0xe4, 0x40, // in al, 64
+		0x88, 0xc7, // mov bh, al
+	))
+	if _, err := sim.Run(); err != nil {
+		t.Fatalf("Error running instructions: %v", err)
+	}
+
+	// the count is latched after the two instructions following the load: 4 + 10 clocks, 3 ticks
+	if bx := sim.readRegister("bx"); bx != 997 {
+		t.Fatalf("Expected latched count 997 but got %d", bx)
+	}
+}
+
+func TestSimulatorTimerWakesHalt(t *testing.T) {
+	tests := []struct {
+		name     string
+		setup    []byte
+		expected uint16
+	}{
+		{name: "interrupts enabled", setup: []byte{0xfb}, expected: 1},                  // sti
+		{name: "irq0 masked", setup: []byte{0xb0, 0x01, 0xe6, 0x21, 0xfb}, expected: 0}, // mov al, 1; out 33, al; sti
+		{name: "interrupts disabled", setup: []byte{}, expected: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body := append(pitProgram(0, 0x34, 100), tt.setup...)
+			body = append(body,
+				0xf4, // hlt
+				0xfa, // cli
+			)
+			sim := NewSimulator(false)
+			sim.writeRegister("cs", 0x100)
+			sim.Load(interruptProgram(8, body, []byte{
+				0x83, 0xc7, 0x01, // add di, 1
+				0xb0, 0x20, // mov al, 0x20
+				0xe6, 0x20, // out 32, al (non-specific EOI)
+				0xcf, // iret
+			}))
+			if _, err := sim.Run(); err != nil {
+				t.Fatalf("Error running instructions: %v", err)
+			}
+
+			if di := sim.readRegister("di"); di != tt.expected {
+				t.Fatalf("Expected %d timer interrupts but got %d", tt.expected, di)
+			}
+			if !sim.Halted() || sim.flags["I"] && tt.expected == 1 {
+				t.Fatalf("Expected the program to end on a HLT with interrupts disabled")
+			}
+		})
+	}
+}
@@ -0,0 +1,196 @@
+package simulator
+
+import (
+	"io"
+)
+
+const (
+	// com1Port is the base port of the first serial port; the UART uses it and the next seven.
+	com1Port uint16 = 0x3F8
+	// SerialIRQ is the interrupt request line COM1 is wired to.
+	SerialIRQ = 4
+)
+
+// UART register offsets from the base port.
+const (
+	uartData          = 0 // receive buffer, transmit holding register or divisor low byte
+	uartInterrupts    = 1 // interrupt enable register or divisor high byte
+	uartIdentify      = 2 // interrupt identification on reads, FIFO control on writes
+	uartLineControl   = 3
+	uartModemControl  = 4
+	uartLineStatus    = 5
+	uartModemStatus   = 6
+	uartScratch       = 7
+	uartRegisterCount = 8
+)
+
+// Register bits.
+const (
+	ierReceived      = 0x01
+	ierTransmitEmpty = 0x02
+	lcrDivisorLatch  = 0x80
+	// mcrOut2 gates the interrupt output onto the bus on the PC.
+	mcrOut2         = 0x08
+	lsrDataReady    = 0x01
+	lsrTransmitIdle = 0x60
+	iirNone         = 0x01
+	iirTransmit     = 0x02
+	iirReceived     = 0x04
+)
+
+// uart emulates an 8250 serial port whose line is connected to host streams. Bytes go out as soon as
+// they are written. A byte comes in when the guest looks for one with the receive buffer empty, by
+// reading the line status or interrupt identification, or by waiting for received-data interrupts.
+// The host stream is read right then, so the same input gives the same trace; the baud rate has no
+// effect.
+type uart struct {
+	ier, lcr, mcr, scratch byte
+	divisor                uint16
+	// received holds the byte in the receive buffer while dataReady is set.
+	received  byte
+	dataReady bool
+	// transmitEmpty is the pending transmitter holding register empty interrupt.
+	transmitEmpty bool
+	// line is the interrupt output, so only its rising edges reach the PIC.
+	line  bool
+	in    io.Reader
+	out   io.Writer
+	raise func(irq int)
+}
+
+// AttachSerial connects COM1 to host streams: bytes the guest transmits are written to out, and bytes
+// read from in arrive in its receive buffer. in is read one byte at a time while the simulator runs,
+// and a read that blocks holds up the simulation until the host produces the byte. The connection
+// is kept across Init.
+func (s *Simulator) AttachSerial(in io.Reader, out io.Writer) {
+	s.uart.in = in
+	s.uart.out = out
+}
+
+// DetachSerial disconnects COM1 from the host streams. Nothing more is read from them, transmitted
+// bytes are dropped and the line reads as idle.
+func (s *Simulator) DetachSerial() {
+	s.uart.in = nil
+	s.uart.out = nil
+}
+
+func (u *uart) In(port uint16) byte {
+	switch port - com1Port {
+	case uartData:
+		if u.lcr&lcrDivisorLatch != 0 {
+			return byte(u.divisor)
+		}
+		value := u.received
+		// the line drops before the next byte comes in, so that byte raises its own interrupt
+		u.dataReady = false
+		u.update()
+		return value
+	case uartInterrupts:
+		if u.lcr&lcrDivisorLatch != 0 {
+			return byte(u.divisor >> 8)
+		}
+		return u.ier
+	case uartIdentify:
+		u.receive()
+		id := u.identify()
+		if id == iirTransmit {
+			// reading the identification acknowledges the transmitter interrupt
+			u.transmitEmpty = false
+			u.update()
+		}
+		return id
+	case uartLineControl:
+		return u.lcr
+	case uartModemControl:
+		return u.mcr
+	case uartLineStatus:
+		u.receive()
+		status := byte(lsrTransmitIdle)
+		if u.dataReady {
+			status |= lsrDataReady
+		}
+		return status
+	case uartModemStatus:
+		// clear to send, data set ready and carrier detect
+		return 0xB0
+	default:
+		return u.scratch
+	}
+}
+
+func (u *uart) Out(port uint16, value byte) {
+	switch port - com1Port {
+	case uartData:
+		if u.lcr&lcrDivisorLatch != 0 {
+			u.divisor = u.divisor&0xFF00 | uint16(value)
+			return
+		}
+		if u.out != nil {
+			u.out.Write([]byte{value})
+		}
+		// the byte leaves at once, so the holding register is immediately empty again
+		u.transmitEmpty = true
+	case uartInterrupts:
+		if u.lcr&lcrDivisorLatch != 0 {
+			u.divisor = u.divisor&0x00FF | uint16(value)<<8
+			return
+		}
+		// enabling the transmitter interrupt with an empty holding register raises it right away
+		if value&^u.ier&ierTransmitEmpty != 0 {
+			u.transmitEmpty = true
+		}
+		u.ier = value & 0x0F
+	case uartLineControl:
+		u.lcr = value
+	case uartModemControl:
+		u.mcr = value & 0x1F
+	case uartScratch:
+		u.scratch = value
+	}
+	u.update()
+}
+
+// identify returns the highest priority pending interrupt.
+func (u *uart) identify() byte {
+	switch {
+	case u.dataReady && u.ier&ierReceived != 0:
+		return iirReceived
+	case u.transmitEmpty && u.ier&ierTransmitEmpty != 0:
+		return iirTransmit
+	default:
+		return iirNone
+	}
+}
+
+// advance gives a guest waiting for received-data interrupts its next byte. A polling guest gets it
+// when it reads the status instead, so the host is not read while nothing is waiting for input.
+func (u *uart) advance() {
+	if u.ier&ierReceived != 0 && u.mcr&mcrOut2 != 0 {
+		u.receive()
+	}
+}
+
+// receive reads the next byte from the host into an empty receive buffer. The end of the host stream
+// detaches it.
+func (u *uart) receive() {
+	if u.dataReady || u.in == nil {
+		return
+	}
+	var b [1]byte
+	if _, err := io.ReadFull(u.in, b[:]); err != nil {
+		u.in = nil
+		return
+	}
+	u.received = b[0]
+	u.dataReady = true
+	u.update()
+}
+
+// update drives the interrupt line, raising IRQ4 on a rising edge.
+func (u *uart) update() {
+	line := u.identify() != iirNone && u.mcr&mcrOut2 != 0
+	if line && !u.line {
+		u.raise(SerialIRQ)
+	}
+	u.line = line
+}
@@ -0,0 +1,137 @@
+package simulator
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSimulatorSerialPolling(t *testing.T) {
+	sim := NewSimulator(false)
+	var out strings.Builder
+	sim.AttachSerial(strings.NewReader("abc"), &out)
+	sim.Load([]byte{
+		0xb9, 0x03, 0x00, // mov cx, 3
+		0xba, 0xfd, 0x03, // l: mov dx, 0x3fd
+		0xec,       // w: in al, dx
+		0xa8, 0x01, // test al, 1
+		0x74, 0xfb, // jz w
+		0xba, 0xf8, 0x03, // mov dx, 0x3f8
+		0xec,       // in al, dx
+		0x04, 0x01, // add al, 1
+		0xee,       // out dx, al
+		0xe2, 0xef, // loop l
+		0xf4, // hlt
+	})
+	if _, err := sim.Run(); err != nil {
+		t.Fatalf("Error running instructions: %v", err)
+	}
+
+	if out.String() != "bcd" {
+		t.Fatalf("Expected %q to be transmitted but got %q", "bcd", out.String())
+	}
+}
+
+func TestSimulatorSerialInterrupts(t *testing.T) {
+	sim := NewSimulator(false)
+	sim.AttachSerial(strings.NewReader("xy"), nil)
+	sim.writeRegister("cs", 0x100)
+	sim.Load(interruptProgram(0x08+SerialIRQ, []byte{
+		0xba, 0xfc, 0x03, // mov dx, 0x3fc
+		0xb0, 0x08, // mov al, 8 (OUT2)
+		0xee,             // out dx, al
+		0xba, 0xf9, 0x03, // mov dx, 0x3f9
+		0xb0, 0x01, // mov al, 1 (received data interrupt)
+		0xee,             // out dx, al
+		0xfb,             // sti
+		0x83, 0xff, 0x02, // w: cmp di, 2
+		0x75, 0xfb, // jne w
+		0xfa, // cli
+	}, []byte{
+		0xba, 0xf8, 0x03, // mov dx, 0x3f8
+		0xec,       // in al, dx
+		0x88, 0xdf, // mov bh, bl
+		0x88, 0xc3, // mov bl, al
+		0x83, 0xc7, 0x01, // add di, 1
+		0xb0, 0x20, // mov al, 0x20
+		0xe6, 0x20, // out 32, al (non-specific EOI)
+		0xcf, // iret
+	}))
+	if _, err := sim.Run(); err != nil {
+		t.Fatalf("Error running instructions: %v", err)
+	}
+
+	if bx := sim.readRegister("bx"); bx != 'x'<<8|'y' {
+		t.Fatalf("Expected bx to hold the received bytes 0x%x but got 0x%x", 'x'<<8|'y', bx)
+	}
+}
+
+func TestSimulatorSerialFirstRead(t *testing.T) {
+	program := []byte{
+		0xba, 0xfd, 0x03, // mov dx, 0x3fd
+		0xec,       // in al, dx
+		0x88, 0xc3, // mov bl, al
+		0xba, 0xf8, 0x03, // mov dx, 0x3f8
+		0xec,       // in al, dx
+		0x88, 0xc7, // mov bh, al
+		0xba, 0xfd, 0x03, // mov dx, 0x3fd
+		0xec, // in al, dx
+		0xf4, // hlt
+	}
+	tests := []struct {
+		name     string
+		detach   bool
+		expected map[string]uint16
+	}{
+		// the byte is there on the first status read, and the stream ends after it
+		{name: "attached", expected: map[string]uint16{"bx": 'a'<<8 | lsrTransmitIdle | lsrDataReady, "ax": lsrTransmitIdle}},
+		{name: "detached", detach: true, expected: map[string]uint16{"bx": lsrTransmitIdle, "ax": lsrTransmitIdle}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sim := NewSimulator(false)
+			input := strings.NewReader("a")
+			sim.AttachSerial(input, nil)
+			if tt.detach {
+				sim.DetachSerial()
+			}
+			sim.Load(program)
+			if _, err := sim.Run(); err != nil {
+				t.Fatalf("Error running instructions: %v", err)
+			}
+
+			for register, expected := range tt.expected {
+				if got := sim.readRegister(register); got != expected {
+					t.Fatalf("Expected %s to be 0x%x but got 0x%x", register, expected, got)
+				}
+			}
+			if tt.detach && input.Len() != 1 {
+				t.Fatalf("Expected nothing to be read from a detached stream")
+			}
+		})
+	}
+}
+
+func TestSimulatorSerialSurvivesInit(t *testing.T) {
+	sim := NewSimulator(false)
+	var out strings.Builder
+	sim.AttachSerial(strings.NewReader("a"), &out)
+	sim.Init()
+	sim.Load([]byte{
+		0xba, 0xf8, 0x03, // mov dx, 0x3f8
+		0xb0, 0x21, // mov al, '!'
+		0xee,             // out dx, al
+		0xba, 0xfd, 0x03, // mov dx, 0x3fd
+		0xec, // in al, dx
+	})
+	if _, err := sim.Run(); err != nil {
+		t.Fatalf("Error running instructions: %v", err)
+	}
+
+	if out.String() != "!" {
+		t.Fatalf("Expected %q to be transmitted but got %q", "!", out.String())
+	}
+	if al := sim.readRegister("al"); al&lsrDataReady == 0 {
+		t.Fatalf("Expected received data after Init but got line status 0x%x", al)
+	}
+}
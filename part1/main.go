@@ -1,17 +1,221 @@
 package main
 
 import (
+	"fmt"
 	"log"
 	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
 
 	"github.com/8086-simulator/part1/internal/decoder"
+	"github.com/8086-simulator/part1/internal/memory"
 	"github.com/8086-simulator/part1/internal/simulator"
 )
 
 const (
 	ExecMode = "exec"
+	// PrefetchTiming is an exec mode option; it selects the prefetch queue timing model.
+	PrefetchTiming = "prefetch"
+	// ScreenOutput is an exec mode option; it renders the CGA text screen with ANSI colors after the run.
+	ScreenOutput = "screen"
+	// TextOutput is an exec mode option; it prints the CGA text screen as plain text after the run.
+	TextOutput = "text"
+	// PNGOutput is an exec mode option, png=<file>,<address>,<width>x<height>[,rgba|indexed][,at=<address>];
+	// it writes the framebuffer at the physical address to a PNG file after the run. With at= it also
+	// writes a numbered snapshot, e.g. frame_1.png, each time execution reaches the instruction at that
+	// address, which is physical or a segment:offset pair.
+	PNGOutput = "png="
+	// MemoryDump is an exec mode option, dump[=<file>[,<from>-<to>]]; it writes memory to a binary file
+	// after the run. The range bounds are physical addresses or segment:offset pairs, the end excluded;
+	// without one the whole megabyte is written, to sim86_memory_0.data unless a file is given.
+	MemoryDump = "dump"
+	// SerialConsole is an exec mode option; it connects COM1 to stdin and stdout.
+	SerialConsole = "serial"
+	// SpeakerOutput is an exec mode option, wav=<file>; it renders the PC speaker to a WAV file after the run.
+	SpeakerOutput = "wav="
+	// CommandTail is an exec mode option, args=<text>; it passes the text to a DOS program as its command
+	// tail, e.g. args="/v input.txt".
+	CommandTail = "args="
+	// defaultDumpFile matches the course's reference simulator.
+	defaultDumpFile = "sim86_memory_0.data"
 )
 
+// pixelFormats name the framebuffer formats of the png option.
+var pixelFormats = map[string]simulator.PixelFormat{
+	"rgba":    simulator.PixelRGBA32,
+	"indexed": simulator.PixelIndexed8,
+}
+
+// cpuModels are exec mode options; they print the trace with clock estimates.
+var cpuModels = map[string]simulator.CPUModel{
+	"8086": simulator.CPU8086,
+	"8088": simulator.CPU8088,
+}
+
+// execOptions are the options following the exec mode argument, in any order.
+type execOptions struct {
+	estimate bool
+	model    simulator.CPUModel
+	prefetch bool
+	screen   bool
+	text     bool
+	pngFile  string
+	png      simulator.Framebuffer
+	// pngAt is the address of the instruction that triggers a snapshot when pngOnBreakpoint is set.
+	pngAt           uint32
+	pngOnBreakpoint bool
+	dumpFile        string
+	// dumpStart and dumpEnd are the physical address range to dump.
+	dumpStart uint32
+	dumpEnd   uint32
+	wavFile   string
+	serial    bool
+	// commandTail is stored in the PSP of DOS programs, with the leading space DOS adds.
+	commandTail string
+}
+
+func parseExecOptions(args []string) (execOptions, error) {
+	var options execOptions
+	for _, arg := range args {
+		if model, ok := cpuModels[arg]; ok {
+			options.estimate = true
+			options.model = model
+			continue
+		}
+		if value, ok := strings.CutPrefix(arg, PNGOutput); ok {
+			file, fb, at, onBreakpoint, err := parseFramebuffer(value)
+			if err != nil {
+				return options, err
+			}
+			options.pngFile = file
+			options.png = fb
+			options.pngAt, options.pngOnBreakpoint = at, onBreakpoint
+			continue
+		}
+		if text, ok := strings.CutPrefix(arg, CommandTail); ok {
+			if text != "" {
+				options.commandTail = " " + text
+			}
+			continue
+		}
+		if file, ok := strings.CutPrefix(arg, SpeakerOutput); ok {
+			options.wavFile = file
+			continue
+		}
+		if arg == MemoryDump || strings.HasPrefix(arg, MemoryDump+"=") {
+			file, start, end, err := parseDump(strings.TrimPrefix(strings.TrimPrefix(arg, MemoryDump), "="))
+			if err != nil {
+				return options, err
+			}
+			options.dumpFile, options.dumpStart, options.dumpEnd = file, start, end
+			continue
+		}
+		switch arg {
+		case PrefetchTiming:
+			options.prefetch = true
+		case ScreenOutput:
+			options.screen = true
+		case TextOutput:
+			options.text = true
+		case SerialConsole:
+			options.serial = true
+		default:
+			return options, fmt.Errorf("unknown exec option: %s", arg)
+		}
+	}
+	return options, nil
+}
+
+// parseFramebuffer parses the value of the png option. The fields after the size are a pixel format
+// and the at= snapshot address, in either order.
+func parseFramebuffer(value string) (string, simulator.Framebuffer, uint32, bool, error) {
+	var fb simulator.Framebuffer
+	fields := strings.Split(value, ",")
+	if len(fields) < 3 || len(fields) > 5 {
+		return "", fb, 0, false, fmt.Errorf("png option needs <file>,<address>,<width>x<height>[,rgba|indexed][,at=<address>]: %s", value)
+	}
+	address, err := strconv.ParseUint(fields[1], 0, 20)
+	if err != nil {
+		return "", fb, 0, false, fmt.Errorf("invalid framebuffer address %s: %w", fields[1], err)
+	}
+	fb.Address = uint32(address)
+	if _, err := fmt.Sscanf(fields[2], "%dx%d", &fb.Width, &fb.Height); err != nil {
+		return "", fb, 0, false, fmt.Errorf("invalid framebuffer size %s: %w", fields[2], err)
+	}
+	fb.Format = simulator.PixelRGBA32
+	var at uint32
+	var onBreakpoint bool
+	for _, field := range fields[3:] {
+		if value, ok := strings.CutPrefix(field, "at="); ok && !onBreakpoint {
+			if at, err = parseAddress(value); err != nil {
+				return "", fb, 0, false, err
+			}
+			onBreakpoint = true
+			continue
+		}
+		format, ok := pixelFormats[field]
+		if !ok {
+			return "", fb, 0, false, fmt.Errorf("unknown png option field: %s", field)
+		}
+		fb.Format = format
+	}
+	return fields[0], fb, at, onBreakpoint, nil
+}
+
+// parseDump parses the value of the dump option.
+func parseDump(value string) (string, uint32, uint32, error) {
+	file, bounds, hasRange := strings.Cut(value, ",")
+	if file == "" {
+		file = defaultDumpFile
+	}
+	if !hasRange {
+		return file, 0, memory.Size, nil
+	}
+	from, to, ok := strings.Cut(bounds, "-")
+	if !ok {
+		return "", 0, 0, fmt.Errorf("dump range needs <from>-<to>: %s", bounds)
+	}
+	start, err := parseAddress(from)
+	if err != nil {
+		return "", 0, 0, err
+	}
+	end, err := parseAddress(to)
+	if err != nil {
+		return "", 0, 0, err
+	}
+	if start > end {
+		return "", 0, 0, fmt.Errorf("dump range starts after it ends: %s", bounds)
+	}
+	return file, start, end, nil
+}
+
+// parseAddress parses a physical address or a segment:offset pair. Either form can be at most
+// memory.Size, one past the last address, since it may end a range.
+func parseAddress(value string) (uint32, error) {
+	if segment, offset, ok := strings.Cut(value, ":"); ok {
+		seg, err := strconv.ParseUint(segment, 0, 16)
+		if err != nil {
+			return 0, fmt.Errorf("invalid segment %s: %w", segment, err)
+		}
+		off, err := strconv.ParseUint(offset, 0, 16)
+		if err != nil {
+			return 0, fmt.Errorf("invalid offset %s: %w", offset, err)
+		}
+		// FFFF:FFFF is past the megabyte; the pair is not wrapped like the CPU does
+		addr := uint32(seg)<<4 + uint32(off)
+		if addr > memory.Size {
+			return 0, fmt.Errorf("address %s is outside memory", value)
+		}
+		return addr, nil
+	}
+	addr, err := strconv.ParseUint(value, 0, 32)
+	if err != nil || addr > memory.Size {
+		return 0, fmt.Errorf("invalid address %s", value)
+	}
+	return uint32(addr), nil
+}
+
 func main() {
 	argsWithoutProg := os.Args[1:]
 	if len(argsWithoutProg) == 0 {
@@ -23,18 +227,143 @@ func main() {
 		log.Fatalf("Error reading file: %v", err)
 	}
 
-	dec := decoder.NewDecoder()
-	instructions, err := dec.Decode(content)
-	if err != nil {
-		log.Fatalf("Error decoding data: %v", err)
-	}
-
 	if len(argsWithoutProg) > 1 && argsWithoutProg[1] == ExecMode {
 		sim := simulator.NewSimulator(false)
 		sim.Init()
-		_, err := sim.Run(instructions)
+		options, err := parseExecOptions(argsWithoutProg[2:])
+		if err != nil {
+			log.Fatal(err)
+		}
+		if options.estimate {
+			sim.EstimateClocks(options.model)
+		}
+		if options.prefetch {
+			sim.SetTimingModel(simulator.TimingPrefetch)
+		}
+		if options.serial {
+			sim.AttachSerial(os.Stdin, os.Stdout)
+		}
+		// DOS programs see the directory they were loaded from as drive C:
+		extension := strings.ToLower(filepath.Ext(argsWithoutProg[0]))
+		if extension == ".com" || extension == ".exe" {
+			if err := sim.MountDrive(filepath.Dir(argsWithoutProg[0])); err != nil {
+				log.Fatalf("Error mounting drive: %v", err)
+			}
+		}
+		switch extension {
+		case ".com":
+			err = sim.LoadCOM(content, options.commandTail)
+		case ".exe":
+			err = sim.LoadEXE(content, options.commandTail)
+		case ".img":
+			// the image is opened for writing too, so INT 13h writes reach the file
+			disk, openErr := os.OpenFile(argsWithoutProg[0], os.O_RDWR, 0)
+			if openErr != nil {
+				log.Fatalf("Error opening floppy image: %v", openErr)
+			}
+			defer disk.Close()
+			err = sim.BootFloppy(disk, int64(len(content)))
+		case ".rom":
+			err = sim.LoadROM(content)
+		default:
+			sim.Load(content)
+		}
+		if err != nil {
+			log.Fatalf("Error loading program: %v", err)
+		}
+		if options.pngOnBreakpoint {
+			snapshots := 0
+			sim.SetBreakpoint(options.pngAt, func() error {
+				snapshots++
+				return writePNG(sim, snapshotFile(options.pngFile, snapshots), options.png)
+			})
+		}
+		results, err := sim.Run()
 		if err != nil {
 			log.Fatalf("Error running instructions: %v", err)
 		}
+		if options.estimate {
+			for _, result := range results {
+				fmt.Println(result.Text)
+			}
+			fmt.Printf("Total clocks: %d\n", sim.Clocks())
+			stats := sim.BusStats()
+			fmt.Printf("Bus transfers: %d (%d unaligned word accesses)\n", stats.Transfers, stats.UnalignedWords)
+		}
+		if sim.Terminated() {
+			fmt.Printf("Exit code: %d\n", sim.ExitCode())
+		}
+		if options.screen {
+			if err := sim.RenderTextScreen(os.Stdout); err != nil {
+				log.Fatalf("Error rendering screen: %v", err)
+			}
+		}
+		if options.text {
+			fmt.Print(sim.TextScreen())
+		}
+		if options.dumpFile != "" {
+			if err := writeDump(sim, options.dumpFile, options.dumpStart, options.dumpEnd); err != nil {
+				log.Fatalf("Error dumping memory: %v", err)
+			}
+		}
+		if options.wavFile != "" {
+			if err := writeWAV(sim, options.wavFile); err != nil {
+				log.Fatalf("Error writing audio: %v", err)
+			}
+		}
+		if options.pngFile != "" {
+			if err := writePNG(sim, options.pngFile, options.png); err != nil {
+				log.Fatalf("Error writing image: %v", err)
+			}
+		}
+		return
+	}
+
+	dec := decoder.NewDecoder()
+	_, err = dec.Decode(content)
+	if err != nil {
+		log.Fatalf("Error decoding data: %v", err)
+	}
+}
+
+func writePNG(sim *simulator.Simulator, file string, fb simulator.Framebuffer) error {
+	f, err := os.Create(file)
+	if err != nil {
+		return err
+	}
+	if err := sim.ExportPNG(f, fb); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// snapshotFile numbers the png file for the nth snapshot, e.g. frame_1.png for frame.png.
+func snapshotFile(file string, n int) string {
+	extension := filepath.Ext(file)
+	return fmt.Sprintf("%s_%d%s", strings.TrimSuffix(file, extension), n, extension)
+}
+
+func writeDump(sim *simulator.Simulator, file string, start, end uint32) error {
+	f, err := os.Create(file)
+	if err != nil {
+		return err
+	}
+	if err := sim.Memory.Dump(f, start, end); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func writeWAV(sim *simulator.Simulator, file string) error {
+	f, err := os.Create(file)
+	if err != nil {
+		return err
+	}
+	if err := sim.WriteSpeakerWAV(f); err != nil {
+		f.Close()
+		return err
 	}
+	return f.Close()
 }
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/8086-simulator/part1/internal/memory"
+	"github.com/8086-simulator/part1/internal/simulator"
+)
+
+func TestParseDump(t *testing.T) {
+	tests := []struct {
+		name          string
+		value         string
+		expectedFile  string
+		expectedStart uint32
+		expectedEnd   uint32
+		expectedError bool
+	}{
+		{name: "default file and full range", value: "", expectedFile: defaultDumpFile, expectedEnd: memory.Size},
+		{name: "file only", value: "out.data", expectedFile: "out.data", expectedEnd: memory.Size},
+		{name: "physical range", value: "out.data,0x100-0x200", expectedFile: "out.data", expectedStart: 0x100, expectedEnd: 0x200},
+		{name: "default file with a range", value: ",0xb8000-0xbc000", expectedFile: defaultDumpFile, expectedStart: 0xb8000, expectedEnd: 0xbc000},
+		{name: "segment offset range", value: "out.data,0x1000:0x100-0x1000:0x200", expectedFile: "out.data", expectedStart: 0x10100, expectedEnd: 0x10200},
+		{name: "range up to the end of memory", value: "out.data,0xf0000-0x100000", expectedFile: "out.data", expectedStart: 0xf0000, expectedEnd: memory.Size},
+		{name: "empty range", value: "out.data,0x100-0x100", expectedFile: "out.data", expectedStart: 0x100, expectedEnd: 0x100},
+		{name: "missing range separator", value: "out.data,0x100", expectedError: true},
+		{name: "physical address past memory", value: "out.data,0-0x100001", expectedError: true},
+		{name: "segment offset past memory", value: "out.data,0-0xffff:0xffff", expectedError: true},
+		{name: "start after end", value: "out.data,0x200-0x100", expectedError: true},
+		{name: "invalid segment", value: "out.data,0x10000:0-0x100", expectedError: true},
+		{name: "invalid address", value: "out.data,x-0x100", expectedError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file, start, end, err := parseDump(tt.value)
+			if tt.expectedError {
+				if err == nil {
+					t.Fatalf("Expected an error but got %s %x-%x", file, start, end)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Error parsing dump option: %v", err)
+			}
+
+			if file != tt.expectedFile || start != tt.expectedStart || end != tt.expectedEnd {
+				t.Fatalf("Expected %s %x-%x but got %s %x-%x", tt.expectedFile, tt.expectedStart, tt.expectedEnd, file, start, end)
+			}
+		})
+	}
+}
+
+func TestParseExecOptionsCommandTail(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		expected string
+	}{
+		{name: "no args option", args: []string{"8086"}, expected: ""},
+		{name: "empty args", args: []string{"args="}, expected: ""},
+		{name: "args with spaces", args: []string{"args=/v input.txt", "text"}, expected: " /v input.txt"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			options, err := parseExecOptions(tt.args)
+			if err != nil {
+				t.Fatalf("Error parsing exec options: %v", err)
+			}
+
+			if options.commandTail != tt.expected {
+				t.Fatalf("Expected command tail %q but got %q", tt.expected, options.commandTail)
+			}
+		})
+	}
+}
+
+func TestParseFramebuffer(t *testing.T) {
+	tests := []struct {
+		name                 string
+		value                string
+		expectedFile         string
+		expectedFramebuffer  simulator.Framebuffer
+		expectedAt           uint32
+		expectedOnBreakpoint bool
+		expectedError        bool
+	}{
+		{name: "default format", value: "out.png,0xa0000,320x200", expectedFile: "out.png", expectedFramebuffer: simulator.Framebuffer{Address: 0xa0000, Width: 320, Height: 200, Format: simulator.PixelRGBA32}},
+		{name: "indexed", value: "out.png,0xa0000,320x200,indexed", expectedFile: "out.png", expectedFramebuffer: simulator.Framebuffer{Address: 0xa0000, Width: 320, Height: 200, Format: simulator.PixelIndexed8}},
+		{name: "snapshot address", value: "out.png,0x100,2x2,at=0x1000:0x20", expectedFile: "out.png", expectedFramebuffer: simulator.Framebuffer{Address: 0x100, Width: 2, Height: 2, Format: simulator.PixelRGBA32}, expectedAt: 0x10020, expectedOnBreakpoint: true},
+		{name: "snapshot address before the format", value: "out.png,0x100,2x2,at=0,indexed", expectedFile: "out.png", expectedFramebuffer: simulator.Framebuffer{Address: 0x100, Width: 2, Height: 2, Format: simulator.PixelIndexed8}, expectedOnBreakpoint: true},
+		{name: "missing size", value: "out.png,0x100", expectedError: true},
+		{name: "unknown field", value: "out.png,0x100,2x2,bgr", expectedError: true},
+		{name: "invalid snapshot address", value: "out.png,0x100,2x2,at=x", expectedError: true},
+		{name: "two snapshot addresses", value: "out.png,0x100,2x2,at=0,at=1", expectedError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file, fb, at, onBreakpoint, err := parseFramebuffer(tt.value)
+			if tt.expectedError {
+				if err == nil {
+					t.Fatalf("Expected an error but got %s %+v", file, fb)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Error parsing png option: %v", err)
+			}
+
+			if file != tt.expectedFile || fb != tt.expectedFramebuffer || at != tt.expectedAt || onBreakpoint != tt.expectedOnBreakpoint {
+				t.Fatalf("Expected %s %+v at 0x%x (%t) but got %s %+v at 0x%x (%t)", tt.expectedFile, tt.expectedFramebuffer, tt.expectedAt, tt.expectedOnBreakpoint, file, fb, at, onBreakpoint)
+			}
+		})
+	}
+
+	if file := snapshotFile("frames/out.png", 3); file != "frames/out_3.png" {
+		t.Fatalf("Expected snapshot file frames/out_3.png but got %s", file)
+	}
+}
@@ -0,0 +1,46 @@
+// Package simulator is the importable API of the 8086 simulator. It re-exports the simulator from
+// the internal packages, so other modules can run programs and attach their own port devices.
+package simulator
+
+import (
+	"github.com/8086-simulator/part1/internal/simulator"
+)
+
+type (
+	// Simulator runs 8086 programs; see NewSimulator.
+	Simulator = simulator.Simulator
+	// Result is the trace of one executed instruction.
+	Result = simulator.Result
+	// PortDevice is a peripheral attached to the I/O address space with Simulator.AttachPortDevice.
+	// Word IN and OUT reach devices as two byte accesses, the low byte at the given port and the high
+	// byte at the next one.
+	PortDevice = simulator.PortDevice
+	// CPUModel selects the bus width used for clock estimates.
+	CPUModel = simulator.CPUModel
+	// TimingModel selects how clock estimates are computed.
+	TimingModel = simulator.TimingModel
+	// Framebuffer describes an image stored row by row in memory, for Simulator.ExportPNG.
+	Framebuffer = simulator.Framebuffer
+	// PixelFormat is the layout of the pixels in a framebuffer.
+	PixelFormat = simulator.PixelFormat
+)
+
+// Models for Simulator.EstimateClocks and Simulator.SetTimingModel.
+const (
+	CPU8086        = simulator.CPU8086
+	CPU8088        = simulator.CPU8088
+	TimingTable    = simulator.TimingTable
+	TimingPrefetch = simulator.TimingPrefetch
+)
+
+// Pixel formats for Framebuffer.
+const (
+	PixelIndexed8 = simulator.PixelIndexed8
+	PixelRGBA32   = simulator.PixelRGBA32
+)
+
+// NewSimulator returns a simulator with its built-in devices attached. printIPRegister adds IP
+// changes to the trace.
+func NewSimulator(printIPRegister bool) *Simulator {
+	return simulator.NewSimulator(printIPRegister)
+}
@@ -0,0 +1,44 @@
+package simulator_test
+
+import (
+	"testing"
+
+	"github.com/8086-simulator/part1/simulator"
+)
+
+// counterDevice counts the bytes written to its port and reads back the count.
+type counterDevice struct {
+	writes byte
+}
+
+func (d *counterDevice) In(port uint16) byte {
+	return d.writes
+}
+
+func (d *counterDevice) Out(port uint16, value byte) {
+	d.writes++
+}
+
+func TestSimulatorAttachPortDevice(t *testing.T) {
+	sim := simulator.NewSimulator(false)
+	device := &counterDevice{}
+	var _ simulator.PortDevice = device
+	sim.AttachPortDevice(device, 0x300)
+	sim.Load([]byte{
+		0xba, 0x00, 0x03, // mov dx, 0x300
+		0xee, // out dx, al
+		0xee, // out dx, al
+		0xec, // in al, dx
+	})
+	results, err := sim.Run()
+	if err != nil {
+		t.Fatalf("Error running instructions: %v", err)
+	}
+
+	if device.writes != 2 {
+		t.Fatalf("Expected 2 writes but got %d", device.writes)
+	}
+	if text := results[len(results)-1].Text; text != "in al, dx ; ax:0x0->0x2 in[0x300]=0x2" {
+		t.Fatalf("Expected the read of the count in the trace but got %s", text)
+	}
+}